@@ -16,6 +16,7 @@ package gofmbt
 
 import (
 	"fmt"
+	"slices"
 )
 
 // Action, associated with a state change, specifies what to execute
@@ -24,6 +25,10 @@ type Action struct {
 	name   string
 	format string
 	args   []interface{}
+	output string  // Observable output expected when the action is executed.
+	weight float64 // Relative probability of choosing the action in random walks.
+	cost   float64 // Cost of executing the action.
+	tags   []string
 }
 
 // NewAction creates a new action.
@@ -32,6 +37,8 @@ func NewAction(format string, args ...interface{}) *Action {
 		format: format,
 		args:   args,
 		name:   fmt.Sprintf(format, args...),
+		weight: 1,
+		cost:   1,
 	}
 }
 
@@ -40,6 +47,64 @@ func (a *Action) String() string {
 	return a.name
 }
 
+// WithOutput sets the observable output that the system under test
+// is expected to produce when the action is executed. Returns the
+// action itself to allow chaining, for instance
+// OnAction("play").WithOutput("playing").Do(...).
+func (a *Action) WithOutput(format string, args ...interface{}) *Action {
+	a.output = fmt.Sprintf(format, args...)
+	return a
+}
+
+// Output returns the expected observable output of the action.
+func (a *Action) Output() string {
+	return a.output
+}
+
+// WithWeight sets the weight of the action. When steps are chosen
+// randomly, the probability of choosing a step is its weight divided
+// by the sum of weights of all alternative steps. The default weight
+// is 1. Returns the action itself to allow chaining.
+func (a *Action) WithWeight(weight float64) *Action {
+	a.weight = weight
+	return a
+}
+
+// Weight returns the weight of the action.
+func (a *Action) Weight() float64 {
+	return a.weight
+}
+
+// WithCost sets the cost of executing the action, for instance the
+// time it takes. The default cost is 1. Returns the action itself to
+// allow chaining.
+func (a *Action) WithCost(cost float64) *Action {
+	a.cost = cost
+	return a
+}
+
+// Cost returns the cost of executing the action.
+func (a *Action) Cost() float64 {
+	return a.cost
+}
+
+// WithTags adds tags to the action, for instance a feature area like
+// "network" or a requirement like "requirement:REQ-12". Returns the
+// action itself to allow chaining.
+func (a *Action) WithTags(tags ...string) *Action {
+	for _, tag := range tags {
+		if !slices.Contains(a.tags, tag) {
+			a.tags = append(a.tags, tag)
+		}
+	}
+	return a
+}
+
+// Tags returns tags of the action.
+func (a *Action) Tags() []string {
+	return a.tags
+}
+
 // When returns a slice containing transitions if enabled is
 // true. This is a convenience function for When/OnAction/Do modeling
 // syntax.
@@ -0,0 +1,302 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+)
+
+// pathSearch searches for the best path that increases coverage. It
+// walks the tree of paths depth-first and keeps track of strings
+// newly covered by the current path, so that coverage increase of
+// every step is computed only once for paths with a common prefix.
+type pathSearch struct {
+	c          *Coverer
+	m          Walkable
+	stepFilter StepFilter
+	maxLen     int
+	history    int            // Number of history steps in the beginning of path.
+	path       Path           // History steps followed by the current path.
+	newCount   map[string]int // Strings newly covered by the current path.
+	increase   []int          // Number of newly covered strings after each step.
+	cost       []float64      // Cumulative cost of the current path after each step.
+	stepCost   StepCost
+	maxPerStep int // Maximum coverage increase of a step, 0 if unknown.
+	best       *CoverageIncreaseStats
+	bestPath   Path
+	done       bool // True if search can be stopped.
+	ctxDone    <-chan struct{}
+	ctx        context.Context
+	err        error // Context error if the search was interrupted.
+}
+
+func (c *Coverer) newPathSearch(ctx context.Context, m Walkable, maxLen int) *pathSearch {
+	history := min(c.historyLen, len(c.coveredPath)-c.pathStart)
+	ps := &pathSearch{
+		c:        c,
+		m:        m,
+		maxLen:   maxLen,
+		history:  history,
+		path:     make(Path, history+maxLen),
+		newCount: map[string]int{},
+		increase: make([]int, maxLen),
+		cost:     make([]float64, maxLen),
+		stepCost: c.costFunc(),
+		ctx:      ctx,
+		ctxDone:  ctx.Done(),
+	}
+	copy(ps.path, c.coveredPath[len(c.coveredPath)-history:])
+	if c.randomness != BestPathRandomNone {
+		ps.stepFilter = stepShuffler(c.rand)
+	}
+	for _, cf := range c.covFuncs {
+		if cf.maxPerStep == 0 {
+			ps.maxPerStep = 0
+			break
+		}
+		ps.maxPerStep += cf.maxPerStep
+	}
+	return ps
+}
+
+// search searches for the best path among paths that extend the
+// current path of depth steps that ends to state s.
+func (ps *pathSearch) search(s State, depth int) {
+	if depth == ps.maxLen {
+		ps.evaluate(depth)
+		return
+	}
+	steps := ps.m.StepsFrom(s)
+	if ps.stepFilter != nil {
+		steps = ps.stepFilter(steps)
+	}
+	if len(steps) == 0 {
+		ps.evaluate(depth)
+		return
+	}
+	for _, step := range steps {
+		select {
+		case <-ps.ctxDone:
+			ps.err = ps.ctx.Err()
+			ps.done = true
+		default:
+		}
+		if ps.done {
+			return
+		}
+		newlyCovered := ps.push(step, depth)
+		ps.evaluatePrefix(depth)
+		if !ps.prune(depth) {
+			ps.search(step.end, depth+1)
+		}
+		ps.pop(newlyCovered)
+	}
+}
+
+// searchFrom searches for the best path among paths that start with
+// a prefix.
+func (ps *pathSearch) searchFrom(prefix Path) {
+	for depth, step := range prefix {
+		ps.push(step, depth)
+		ps.evaluatePrefix(depth)
+	}
+	ps.search(prefix[len(prefix)-1].end, len(prefix))
+}
+
+// push appends a step to the current path and returns strings that
+// the step covers and that were not covered before.
+func (ps *pathSearch) push(step *Step, depth int) []string {
+	end := ps.history + depth
+	ps.path[end] = step
+	newlyCovered := []string{}
+	for _, cf := range ps.c.covFuncs {
+		for _, s := range cf.coveredAfter(ps.path[:end+1], end) {
+			if ps.c.coverCount[s] == 0 {
+				ps.newCount[s]++
+				newlyCovered = append(newlyCovered, s)
+			}
+		}
+	}
+	ps.increase[depth] = len(ps.newCount)
+	ps.cost[depth] = ps.stepCost(step)
+	if depth > 0 {
+		ps.cost[depth] += ps.cost[depth-1]
+	}
+	return newlyCovered
+}
+
+// pop reverts the coverage of the last step in the current path.
+func (ps *pathSearch) pop(newlyCovered []string) {
+	for _, s := range newlyCovered {
+		ps.newCount[s]--
+		if ps.newCount[s] == 0 {
+			delete(ps.newCount, s)
+		}
+	}
+}
+
+// prune returns true if no extension of the current path, whose last
+// step is at depth, can be strictly better than the best path found
+// so far.
+func (ps *pathSearch) prune(depth int) bool {
+	if ps.best == nil || ps.maxPerStep == 0 || ps.c.stepCost != nil {
+		return false
+	}
+	current := ps.increase[depth]
+	bound := current + (ps.maxLen-depth-1)*ps.maxPerStep
+	if bound < ps.best.MaxIncrease {
+		return true
+	}
+	if bound > ps.best.MaxIncrease {
+		return false
+	}
+	if ps.c.randomness == BestPathRandomAmongMaxCoverageIncrease {
+		return true
+	}
+	if current == ps.best.MaxIncrease {
+		return false
+	}
+	// Max increase can be reached, but can it be reached faster?
+	stepsNeeded := (ps.best.MaxIncrease - current + ps.maxPerStep - 1) / ps.maxPerStep
+	return depth+stepsNeeded > ps.best.MaxStep
+}
+
+// evaluate updates the best path if the current path of depth steps
+// is better.
+func (ps *pathSearch) evaluate(depth int) {
+	if depth == 0 || ps.increase[depth-1] == 0 {
+		// only return paths that increase coverage
+		return
+	}
+	est := &CoverageIncreaseStats{MaxIncrease: ps.increase[depth-1], FirstStep: -1, MaxStep: -1}
+	for i, increase := range ps.increase[:depth] {
+		if est.FirstStep == -1 && increase > 0 {
+			est.FirstStep = i
+			est.FirstIncrease = increase
+		}
+		if increase == est.MaxIncrease {
+			est.MaxStep = i
+			break
+		}
+	}
+	est.MaxCost = ps.cost[est.MaxStep]
+	est.Cost = ps.cost[depth-1]
+	if !ps.c.isBetter(est, ps.best) {
+		return
+	}
+	ps.best = est
+	ps.bestPath = make(Path, depth)
+	copy(ps.bestPath, ps.path[ps.history:])
+	if ps.c.randomness >= BestPathRandomAmongAnyPath {
+		ps.done = true
+	}
+}
+
+// evaluatePrefix evaluates the current path of depth+1 steps as a
+// path of its own, if costs are considered and the last step
+// increased coverage. Then a short cheap path may be better than
+// any of its extensions.
+func (ps *pathSearch) evaluatePrefix(depth int) {
+	if ps.c.stepCost == nil || ps.done {
+		return
+	}
+	if depth > 0 && ps.increase[depth] == ps.increase[depth-1] {
+		return
+	}
+	ps.evaluate(depth + 1)
+}
+
+// parallelBestPath partitions the search for the best path on the
+// first one or two steps, searches partitions in parallel, and
+// combines results in the order of partitions. Returns the context
+// error if the search of any partition was interrupted.
+func (c *Coverer) parallelBestPath(ctx context.Context, m Walkable, s State, maxLen int) (Path, *CoverageIncreaseStats, error) {
+	var filter StepFilter
+	if c.randomness != BestPathRandomNone {
+		filter = stepShuffler(c.rand)
+	}
+	stepsFrom := func(s State) []*Step {
+		steps := m.StepsFrom(s)
+		if filter != nil {
+			steps = filter(steps)
+		}
+		return steps
+	}
+	prefixes := []Path{}
+	for _, step := range stepsFrom(s) {
+		prefixes = append(prefixes, Path{step})
+	}
+	if len(prefixes) < c.workers {
+		secondPrefixes := []Path{}
+		for _, prefix := range prefixes {
+			nextSteps := stepsFrom(prefix[0].end)
+			if len(nextSteps) == 0 {
+				secondPrefixes = append(secondPrefixes, prefix)
+			}
+			for _, step := range nextSteps {
+				secondPrefixes = append(secondPrefixes, Path{prefix[0], step})
+			}
+		}
+		prefixes = secondPrefixes
+	}
+	// Every partition gets its own random number generator so
+	// that the results do not depend on goroutine scheduling.
+	seeds := make([]int64, len(prefixes))
+	if c.randomness != BestPathRandomNone && c.rand != nil {
+		for i := range seeds {
+			seeds[i] = c.rand.Int63()
+		}
+	}
+	results := make([]*pathSearch, len(prefixes))
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for worker := 0; worker < c.workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				ps := c.newPathSearch(ctx, m, maxLen)
+				if ps.stepFilter != nil && c.rand != nil {
+					ps.stepFilter = stepShuffler(rand.New(rand.NewSource(seeds[i])))
+				}
+				ps.searchFrom(prefixes[i])
+				results[i] = ps
+			}
+		}()
+	}
+	for i := range prefixes {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	var best *CoverageIncreaseStats
+	var bestPath Path
+	var err error
+	for _, ps := range results {
+		if err == nil {
+			err = ps.err
+		}
+		if ps.best == nil || !c.isBetter(ps.best, best) {
+			continue
+		}
+		best, bestPath = ps.best, ps.bestPath
+		if c.randomness >= BestPathRandomAmongAnyPath {
+			break
+		}
+	}
+	return bestPath, best, err
+}
@@ -0,0 +1,214 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// bestPathByEstimate finds the best path by estimating coverage
+// increase of every path separately.
+func bestPathByEstimate(c *Coverer, m Walkable, s State, maxLen int) (Path, *CoverageIncreaseStats) {
+	var best *CoverageIncreaseStats
+	var bestPath Path
+	for path := range NewWalker(m).IterPaths(s, maxLen) {
+		if est := c.EstimateCoverageIncrease(path); est.MaxIncrease > 0 && c.isBetter(est, best) {
+			best = est
+			bestPath = append(Path{}, path...)
+		}
+	}
+	return bestPath, best
+}
+
+func TestBestPathMatchesEstimate(t *testing.T) {
+	model := playerModels["when"]
+	for name, cover := range map[string]func(*Coverer){
+		"states":              func(c *Coverer) { c.CoverStates() },
+		"state-actions":       func(c *Coverer) { c.CoverStateActions() },
+		"action-combinations": func(c *Coverer) { c.CoverActionCombinations(3) },
+		"state-combinations":  func(c *Coverer) { c.CoverStateCombinations(2) },
+		"many": func(c *Coverer) {
+			c.CoverActions()
+			c.CoverStates()
+			c.CoverStateActions()
+		},
+		"custom": func(c *Coverer) { c.Cover("actions", 0, ActionNames) },
+	} {
+		coverer := NewCoverer()
+		cover(coverer)
+		state := State(&PlayerState{false, 1})
+		for round := 0; round < 20; round++ {
+			path, stats := coverer.BestPath(model, state, 5)
+			refPath, refStats := bestPathByEstimate(coverer, model, state, 5)
+			if len(path) == 0 || len(refPath) == 0 {
+				if len(path) != len(refPath) {
+					t.Fatalf("%s: round %d: expected path %v, got %v", name, round, refPath, path)
+				}
+				break
+			}
+			if *stats != *refStats {
+				t.Fatalf("%s: round %d: expected stats %+v, got %+v", name, round, *refStats, *stats)
+			}
+			for i := range path {
+				if path[i].String() != refPath[i].String() {
+					t.Fatalf("%s: round %d: expected path %v, got %v", name, round, refPath, path)
+				}
+			}
+			coverer.MarkCovered(path[:stats.FirstStep+1]...)
+			coverer.UpdateCoverage()
+			state = path[stats.FirstStep].EndState()
+		}
+	}
+}
+
+func TestParallelBestPath(t *testing.T) {
+	model := playerModels["when"]
+	runRounds := func(workers int, seed int64, randomness int) []string {
+		coverer := NewCoverer()
+		coverer.CoverStateActions()
+		coverer.CoverActionCombinations(2)
+		coverer.SetBestPathWorkers(workers)
+		if randomness != BestPathRandomNone {
+			coverer.SetBestPathRandom(seed, randomness)
+		}
+		state := State(&PlayerState{false, 1})
+		trace := []string{}
+		for {
+			path, stats := coverer.BestPath(model, state, 6)
+			if len(path) == 0 {
+				return trace
+			}
+			trace = append(trace, fmt.Sprintf("%+v %v", *stats, ActionNames(path)))
+			coverer.MarkCovered(path[:stats.FirstStep+1]...)
+			coverer.UpdateCoverage()
+			state = path[stats.FirstStep].EndState()
+		}
+	}
+	sequential := runRounds(1, 0, BestPathRandomNone)
+	for _, workers := range []int{2, 3, 8} {
+		if parallel := runRounds(workers, 0, BestPathRandomNone); !slices.Equal(parallel, sequential) {
+			t.Fatalf("%d workers: expected %v, got %v", workers, sequential, parallel)
+		}
+	}
+	for _, randomness := range []int{BestPathRandomAmongEquallyGood, BestPathRandomAmongAnyPath} {
+		first := runRounds(4, 42, randomness)
+		for i := 0; i < 3; i++ {
+			if again := runRounds(4, 42, randomness); !slices.Equal(again, first) {
+				t.Fatalf("randomness %d: expected deterministic results %v, got %v", randomness, first, again)
+			}
+		}
+	}
+}
+
+// newWideModel returns a model with width actions in every state.
+func newWideModel(width int) *Model {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ts := []*Transition{}
+		for i := 0; i < width; i++ {
+			ts = append(ts, OnAction("a%d", i).Do(gotoMyState(fmt.Sprintf("%s%d", s, i%2)))...)
+		}
+		return ts
+	})
+	return model
+}
+
+func TestBestPathContext(t *testing.T) {
+	model := newWideModel(10)
+	for _, workers := range []int{1, 4} {
+		coverer := NewCoverer()
+		coverer.CoverStates()
+		coverer.CoverActionCombinations(3)
+		coverer.SetBestPathWorkers(workers)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		started := time.Now()
+		path, stats, err := coverer.BestPathContext(ctx, model, MyState(""), 12)
+		cancel()
+		if err != context.DeadlineExceeded {
+			t.Fatalf("%d workers: expected deadline exceeded, got %v", workers, err)
+		}
+		if len(path) == 0 || stats == nil {
+			t.Fatalf("%d workers: expected the best path found so far, got nothing", workers)
+		}
+		if elapsed := time.Since(started); elapsed > 5*time.Second {
+			t.Fatalf("%d workers: search was not interrupted in time: %s", workers, elapsed)
+		}
+	}
+
+	// Partitions of two steps are complete paths of maxLen 2, so
+	// the search completes even if the context is cancelled.
+	coverer := NewCoverer()
+	coverer.CoverStates()
+	coverer.SetBestPathWorkers(4)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if path, _, err := coverer.BestPathContext(ctx, newWideModel(2), MyState(""), 2); err != nil || len(path) != 2 {
+		t.Fatalf("expected complete search, got path %v and error %v", path, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	for range NewWalker(model).IterPathsContext(ctx, MyState(""), 12) {
+		count++
+		if count == 100 {
+			cancel()
+		}
+	}
+	if count != 100 {
+		t.Fatalf("expected 100 paths before cancellation, got %d", count)
+	}
+}
+
+func BenchmarkBestPathLookahead10(b *testing.B) {
+	model := playerModels["when"]
+	for i := 0; i < b.N; i++ {
+		coverer := NewCoverer()
+		coverer.CoverStateActions()
+		coverer.CoverActionCombinations(2)
+		coverer.BestPath(model, &PlayerState{false, 1}, 10)
+	}
+}
+
+func TestCostAwareBestPath(t *testing.T) {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("cheap").Do(gotoMyState("A"))),
+			When(ms == "start", OnAction("reboot").WithCost(10).Do(gotoMyState("B1"))),
+			When(ms == "B1", OnAction("b").Do(gotoMyState("B2"))),
+			When(ms == "B2", OnAction("b").Do(gotoMyState("B3"))),
+		)
+	})
+	for _, workers := range []int{1, 4} {
+		coverer := NewCoverer()
+		coverer.CoverStates()
+		coverer.SetBestPathWorkers(workers)
+		path, stats := coverer.BestPath(model, MyState("start"), 3)
+		if len(path) != 3 || stats.MaxIncrease != 4 || stats.Cost != 12 || stats.MaxCost != 12 {
+			t.Fatalf("%d workers: expected 3-step path with cost 12, got %v %+v", workers, path, stats)
+		}
+		coverer.SetBestPathCost(ActionCost)
+		path, stats = coverer.BestPath(model, MyState("start"), 3)
+		if len(path) != 1 || path[0].Action().String() != "cheap" || stats.MaxIncrease != 2 || stats.MaxCost != 1 {
+			t.Fatalf("%d workers: expected cheap path, got %v %+v", workers, path, stats)
+		}
+	}
+}
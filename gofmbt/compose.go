@@ -0,0 +1,139 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ProductState is a state of a composed model. It contains a state
+// of every component model.
+type ProductState []State
+
+// String returns states of all components.
+func (ps ProductState) String() string {
+	strs := make([]string, len(ps))
+	for i, s := range ps {
+		strs[i] = s.String()
+	}
+	return "(" + strings.Join(strs, ", ") + ")"
+}
+
+// Compose returns a product model of component models, in CSP-style
+// parallel composition. An action that is in the alphabet of more
+// than one component is synchronized: it is possible only when every
+// component with the action in its alphabet takes it at the same
+// time. Other actions of components are interleaved. Actions are
+// identified by Action.String(). Every component must have an
+// initial state, and its alphabet is explored from there, so
+// components must be finite. The initial state of the product is the
+// ProductState of initial states of components.
+func Compose(models ...*Model) (*Model, error) {
+	if len(models) == 0 {
+		return nil, errors.New("no models to compose")
+	}
+	initial := ProductState{}
+	participants := map[string][]int{}
+	for i, m := range models {
+		if m.initial == nil {
+			return nil, fmt.Errorf("component %d has no initial state", i)
+		}
+		initial = append(initial, m.initial)
+		alphabet := map[string]bool{}
+		for _, step := range m.Explore(m.initial, ExploreLimits{}).Steps {
+			alphabet[step.action.String()] = true
+		}
+		for action := range alphabet {
+			participants[action] = append(participants[action], i)
+		}
+	}
+	product := NewModel()
+	product.SetInitialState(initial)
+	product.From(func(s State) []*Transition {
+		ps := s.(ProductState)
+		ts := []*Transition{}
+		synced := map[string]map[int][]*Transition{}
+		syncedOrder := []string{}
+		for i, m := range models {
+			for _, t := range m.TransitionsFrom(ps[i]) {
+				action := t.action.String()
+				if len(participants[action]) <= 1 {
+					ts = append(ts, NewTransition(t.action, componentStateChange(i, t.stateChange)))
+					continue
+				}
+				if synced[action] == nil {
+					synced[action] = map[int][]*Transition{}
+					syncedOrder = append(syncedOrder, action)
+				}
+				synced[action][i] = append(synced[action][i], t)
+			}
+		}
+		for _, action := range syncedOrder {
+			ts = append(ts, synchronizedTransitions(participants[action], synced[action])...)
+		}
+		return ts
+	})
+	return product, nil
+}
+
+// componentStateChange returns a state change of a product state
+// where only the component i changes.
+func componentStateChange(i int, sc StateChange) StateChange {
+	return func(s State) State {
+		ps := s.(ProductState)
+		end := sc(ps[i])
+		if end == nil {
+			return nil
+		}
+		next := append(ProductState{}, ps...)
+		next[i] = end
+		return next
+	}
+}
+
+// synchronizedTransitions returns transitions where every participant
+// component takes one of its transitions at the same time.
+func synchronizedTransitions(participants []int, componentTs map[int][]*Transition) []*Transition {
+	combinations := [][]*Transition{{}}
+	for _, i := range participants {
+		if len(componentTs[i]) == 0 {
+			return nil
+		}
+		next := [][]*Transition{}
+		for _, comb := range combinations {
+			for _, t := range componentTs[i] {
+				next = append(next, append(comb[:len(comb):len(comb)], t))
+			}
+		}
+		combinations = next
+	}
+	ts := []*Transition{}
+	for _, comb := range combinations {
+		ts = append(ts, NewTransition(comb[0].action, func(s State) State {
+			next := append(ProductState{}, s.(ProductState)...)
+			for n, i := range participants {
+				end := comb[n].stateChange(next[i])
+				if end == nil {
+					return nil
+				}
+				next[i] = end
+			}
+			return next
+		}))
+	}
+	return ts
+}
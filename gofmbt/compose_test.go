@@ -0,0 +1,67 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompose(t *testing.T) {
+	client := NewModel()
+	client.SetInitialState(MyState("idle"))
+	client.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "idle", OnAction("think").Do(gotoMyState("idle"))),
+			When(ms == "idle", OnAction("request").Do(gotoMyState("waiting"))),
+			When(ms == "waiting", OnAction("response").Do(gotoMyState("idle"))),
+		)
+	})
+	server := NewModel()
+	server.SetInitialState(MyState("ready"))
+	server.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "ready", OnAction("request").Do(gotoMyState("busy"))),
+			When(ms == "busy", OnAction("work").Do(gotoMyState("done"))),
+			When(ms == "done", OnAction("response").Do(gotoMyState("ready"))),
+		)
+	})
+	if _, err := Compose(client, NewModel()); err == nil {
+		t.Fatalf("expected error when a component has no initial state")
+	}
+	product, err := Compose(client, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := product.Explore(product.InitialState(), ExploreLimits{})
+	if ss.Initial.String() != "(idle, ready)" {
+		t.Fatalf("unexpected initial state %s", ss.Initial)
+	}
+	expected := []string{
+		"(idle, ready) think (idle, ready)",
+		"(idle, ready) request (waiting, busy)",
+		"(waiting, busy) work (waiting, done)",
+		"(waiting, done) response (idle, ready)",
+	}
+	got := []string{}
+	for _, step := range ss.Steps {
+		got = append(got, strings.Join([]string{step.StartState().String(), step.Action().String(), step.EndState().String()}, " "))
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected steps:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -15,6 +15,9 @@
 package gofmbt
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"math/rand"
 	"strings"
 )
@@ -34,18 +37,37 @@ type CoveredInPath func(Path) []string
 // Coverer combines what is counted as covered, how to count it, and
 // helps finding Paths that increase coverage.
 type Coverer struct {
-	coveredPath Path            // Path that is currently covered.
-	coverCount  map[string]int  // Strings covered by the coveredPath.
-	covFuncs    []CoveredInPath // Functions that return strings covered by a path.
-	historyLen  int             // Length of the history in coveredPath that needs to be considered when estimating coverage increase for new steps that extend the path.
-	rand        *rand.Rand      // Random number generator initialized with a given seed.
-	randomness  int             // Randomness level.
+	coveredPath Path           // Path that is currently covered.
+	coverCount  map[string]int // Strings covered by the coveredPath and imported coverage.
+	importCount map[string]int // Strings covered by imported and merged coverage.
+	updatedLen  int            // Number of steps in the coveredPath counted in coverCount.
+	pathStart   int            // Index of the first step of the current test case in coveredPath.
+	covFuncs    []*coverFunc   // Functions that return strings covered by a path.
+	historyLen  int            // Length of the history in coveredPath that needs to be considered when estimating coverage increase for new steps that extend the path.
+	rand        *rand.Rand     // Random number generator initialized with a given seed.
+	randomness  int            // Randomness level.
+	workers     int            // Number of goroutines in BestPath search.
+	goalPercent float64        // Coverage percentage that reaches the goal, 0 if no goal.
+	goalNames   []string       // Names of coverage functions in the goal, all if empty.
+	stepCost    StepCost       // Cost of steps in BestPath search, nil if not cost-aware.
+}
+
+// coverFunc is a named function that returns strings covered by a
+// path. Strings covered by a step depend at most on window previous
+// steps in the path.
+type coverFunc struct {
+	name       string
+	window     int
+	f          CoveredInPath
+	universe   map[string]bool // All strings that can be covered, nil if unknown.
+	maxPerStep int             // Maximum number of strings covered by a step, 0 if unknown.
 }
 
 // NewCoverer creates a new Coverer.
 func NewCoverer() *Coverer {
 	return &Coverer{
-		coverCount: map[string]int{},
+		coverCount:  map[string]int{},
+		importCount: map[string]int{},
 	}
 }
 
@@ -91,21 +113,18 @@ func StateActionStrings(path Path) []string {
 
 // CoverActions starts counting covered action names.
 func (c *Coverer) CoverActions() {
-	c.addCovFunc(ActionNames)
+	c.addCovFunc("actions", 0, 1, ActionNames)
 }
 
 // CoverActionFormats starts counting covered action formats.
 func (c *Coverer) CoverActionFormats() {
-	c.addCovFunc(ActionFormats)
+	c.addCovFunc("action-formats", 0, 1, ActionFormats)
 }
 
 // CoverActionCombinations starts counting covered action name combinations of length up to combLenMax.
 func (c *Coverer) CoverActionCombinations(combLenMax int) {
-	if c.historyLen < combLenMax {
-		c.historyLen = combLenMax
-	}
 	actionSep := "\x00"
-	c.addCovFunc(func(path Path) []string {
+	c.addCovFunc(fmt.Sprintf("action-combinations(%d)", combLenMax), combLenMax, combLenMax, func(path Path) []string {
 		actionCombs := []string{}
 		for combLen := 1; combLen <= combLenMax; combLen++ {
 			for first := 0; first <= len(path)-combLen; first++ {
@@ -118,11 +137,8 @@ func (c *Coverer) CoverActionCombinations(combLenMax int) {
 
 // CoverActionFormatCombinations starts counting covered action format combinations of length up to combLenMax.
 func (c *Coverer) CoverActionFormatCombinations(combLenMax int) {
-	if c.historyLen < combLenMax {
-		c.historyLen = combLenMax
-	}
 	actionSep := "\x00"
-	c.addCovFunc(func(path Path) []string {
+	c.addCovFunc(fmt.Sprintf("action-format-combinations(%d)", combLenMax), combLenMax, combLenMax, func(path Path) []string {
 		actionCombs := []string{}
 		for combLen := 1; combLen <= combLenMax; combLen++ {
 			for first := 0; first <= len(path)-combLen; first++ {
@@ -135,21 +151,18 @@ func (c *Coverer) CoverActionFormatCombinations(combLenMax int) {
 
 // CoverStates starts counting covered states.
 func (c *Coverer) CoverStates() {
-	c.addCovFunc(StateStrings)
+	c.addCovFunc("states", 1, 2, StateStrings)
 }
 
 // CoverStateActions starts counting covered state-action pairs.
 func (c *Coverer) CoverStateActions() {
-	c.addCovFunc(StateActionStrings)
+	c.addCovFunc("state-actions", 0, 1, StateActionStrings)
 }
 
 // CoverStateCombinations starts counting covered state combinations of length up to combLenMax.
 func (c *Coverer) CoverStateCombinations(combLenMax int) {
-	if c.historyLen < combLenMax {
-		c.historyLen = combLenMax
-	}
 	stateSep := "\x00"
-	c.addCovFunc(func(path Path) []string {
+	c.addCovFunc(fmt.Sprintf("state-combinations(%d)", combLenMax), combLenMax, combLenMax, func(path Path) []string {
 		stateCombs := []string{}
 		for combLen := 1; combLen <= combLenMax; combLen++ {
 			for first := 0; first <= len(path)-combLen; first++ {
@@ -160,14 +173,27 @@ func (c *Coverer) CoverStateCombinations(combLenMax int) {
 	})
 }
 
-func (c *Coverer) addCovFunc(covFunc CoveredInPath) {
-	c.covFuncs = append(c.covFuncs, covFunc)
+// Cover starts counting strings covered by a custom coverage
+// function. Strings covered by a step in a path may depend on at
+// most window previous steps. For instance, the window of
+// CoverStates() is 1, because the start state of a step is the end
+// state of the previous step, and the window of
+// CoverActionCombinations(n) is n.
+func (c *Coverer) Cover(name string, window int, covFunc CoveredInPath) {
+	c.addCovFunc(name, window, 0, covFunc)
+}
+
+func (c *Coverer) addCovFunc(name string, window, maxPerStep int, covFunc CoveredInPath) {
+	if c.historyLen < window {
+		c.historyLen = window
+	}
+	c.covFuncs = append(c.covFuncs, &coverFunc{name: name, window: window, maxPerStep: maxPerStep, f: covFunc})
 }
 
 func (c *Coverer) covFunc(path Path) []string {
 	allCovered := []string{}
 	for _, covFunc := range c.covFuncs {
-		allCovered = append(allCovered, covFunc(path)...)
+		allCovered = append(allCovered, covFunc.f(path)...)
 	}
 	return allCovered
 }
@@ -186,12 +212,38 @@ func (c *Coverer) CoveredStrings() []string {
 	return cs
 }
 
-// UpdateCoverage updates the count of covered strings.
+// UpdateCoverage updates the count of covered strings. Only steps
+// marked covered after the previous update are processed, together
+// with the window of history steps of each coverage function.
 func (c *Coverer) UpdateCoverage() {
-	c.coverCount = map[string]int{}
-	for _, s := range c.covFunc(c.coveredPath) {
-		c.coverCount[s]++
+	for _, cf := range c.covFuncs {
+		for _, s := range cf.coveredAfter(c.coveredPath[c.pathStart:], c.updatedLen-c.pathStart) {
+			c.coverCount[s]++
+		}
 	}
+	c.updatedLen = len(c.coveredPath)
+}
+
+// coveredAfter returns strings covered by steps path[from:] that
+// were not covered by path[:from] already.
+func (cf *coverFunc) coveredAfter(path Path, from int) []string {
+	first := from - cf.window
+	if first < 0 {
+		first = 0
+	}
+	history := map[string]int{}
+	for _, s := range cf.f(path[first:from]) {
+		history[s]++
+	}
+	covered := []string{}
+	for _, s := range cf.f(path[first:]) {
+		if history[s] > 0 {
+			history[s]--
+			continue
+		}
+		covered = append(covered, s)
+	}
+	return covered
 }
 
 // MarkCovered marks a sequence of steps as covered. The sequence is
@@ -201,13 +253,24 @@ func (c *Coverer) MarkCovered(step ...*Step) {
 	c.coveredPath = append(c.coveredPath, step...)
 }
 
+// StartNewPath updates coverage and marks that steps marked covered
+// after this belong to a new test case that starts from the
+// beginning. Combinations of actions or states are not counted over
+// test case boundaries.
+func (c *Coverer) StartNewPath() {
+	c.UpdateCoverage()
+	c.pathStart = len(c.coveredPath)
+}
+
 // CoverageIncreaseStats holds statistics on estimated coverage
 // increase when extending a path.
 type CoverageIncreaseStats struct {
-	MaxStep       int // Index of the step in the path extension after which max increase is reached.
-	MaxIncrease   int // Maximum increase in coverage with the path extension.
-	FirstStep     int // Index of the step in the path extension after which first coverage increase is reached.
-	FirstIncrease int // First increase in coverage with the path extension.
+	MaxStep       int     // Index of the step in the path extension after which max increase is reached.
+	MaxIncrease   int     // Maximum increase in coverage with the path extension.
+	FirstStep     int     // Index of the step in the path extension after which first coverage increase is reached.
+	FirstIncrease int     // First increase in coverage with the path extension.
+	MaxCost       float64 // Cumulative cost of steps up to and including MaxStep.
+	Cost          float64 // Cumulative cost of all steps in the path extension.
 }
 
 // EstimateCoverageIncrease estimates coverage increase when extending
@@ -215,10 +278,7 @@ type CoverageIncreaseStats struct {
 func (c *Coverer) EstimateCoverageIncrease(path Path) *CoverageIncreaseStats {
 	est := &CoverageIncreaseStats{FirstStep: -1, MaxStep: -1}
 	fullCoverCount := map[string]int{}
-	historyLen := c.historyLen
-	if historyLen > len(c.coveredPath) {
-		historyLen = len(c.coveredPath)
-	}
+	historyLen := min(c.historyLen, len(c.coveredPath)-c.pathStart)
 	pathWithHistory := append(c.coveredPath[len(c.coveredPath)-historyLen:], path...)
 	allNewCovered := c.covFunc(pathWithHistory)
 	for _, s := range allNewCovered {
@@ -244,6 +304,13 @@ func (c *Coverer) EstimateCoverageIncrease(path Path) *CoverageIncreaseStats {
 			break
 		}
 	}
+	cost := c.costFunc()
+	for i, step := range path {
+		est.Cost += cost(step)
+		if i == est.MaxStep {
+			est.MaxCost = est.Cost
+		}
+	}
 	return est
 }
 
@@ -254,10 +321,48 @@ func (c *Coverer) SetBestPathRandom(seed int64, randomness int) {
 	c.randomness = randomness
 }
 
-func (c *Coverer) stepShuffler() StepFilter {
+// SetBestPathWorkers sets the number of goroutines that search for
+// the best path in parallel. The search is partitioned on the first
+// one or two steps of paths. Results are equal to results of
+// sequential search, except when steps are shuffled due to
+// randomness. Even then results are deterministic for a given
+// seed. Parallel search requires that StepsFrom of searched models is
+// safe for concurrent use.
+func (c *Coverer) SetBestPathWorkers(workers int) {
+	c.workers = workers
+}
+
+// SetBestPathCost makes BestPath maximize coverage increase per cost
+// instead of coverage increase. Cost of a path is the sum of costs of
+// its steps up to the step that reaches the maximum increase. Use
+// ActionCost to take costs from actions. Paths with equal increase
+// per cost are compared like without costs. Pruning the search is
+// not possible with costs, so searching is slower. Passing nil
+// disables cost-aware search.
+func (c *Coverer) SetBestPathCost(cost StepCost) {
+	c.stepCost = cost
+}
+
+// costFunc returns the function for computing costs of steps.
+func (c *Coverer) costFunc() StepCost {
+	if c.stepCost == nil {
+		return ActionCost
+	}
+	return c.stepCost
+}
+
+// stepShuffler returns a filter that shuffles steps randomly. The
+// probability of a step to be shuffled first is proportional to the
+// weight of its action.
+func stepShuffler(r *rand.Rand) StepFilter {
 	return func(steps []*Step) []*Step {
-		if c.rand != nil {
-			c.rand.Shuffle(len(steps), func(i, j int) {
+		if r != nil {
+			steps = append([]*Step{}, steps...)
+			if !equalWeights(steps) {
+				weightedShuffle(r, steps)
+				return steps
+			}
+			r.Shuffle(len(steps), func(i, j int) {
 				steps[i], steps[j] = steps[j], steps[i]
 			})
 		}
@@ -265,78 +370,72 @@ func (c *Coverer) stepShuffler() StepFilter {
 	}
 }
 
+// BestPath returns a path of at most maxLen steps, starting from a
+// state, that increases coverage the most. Returns nil if coverage
+// cannot be increased. Coverage increase is computed incrementally
+// while descending the tree of paths, so that paths with a common
+// prefix share the work, and subtrees that cannot beat the best path
+// found so far are pruned. See SetBestPathWorkers for parallel search.
 func (c *Coverer) BestPath(m Walkable, s State, maxLen int) (Path, *CoverageIncreaseStats) {
-	var best *CoverageIncreaseStats
-	var bestPath Path
-	updateBest := func(newPath Path, newEst *CoverageIncreaseStats) {
-		bestPath = make(Path, len(newPath))
-		copy(bestPath, newPath)
-		best = newEst
+	path, stats, _ := c.BestPathContext(context.Background(), m, s, maxLen)
+	return path, stats
+}
+
+// BestPathContext is like BestPath, but it stops searching when the
+// context is cancelled or its deadline is exceeded. Then it returns
+// the best path found so far, and the error of the context to flag
+// that the search was incomplete.
+func (c *Coverer) BestPathContext(ctx context.Context, m Walkable, s State, maxLen int) (Path, *CoverageIncreaseStats, error) {
+	if c.workers > 1 && maxLen > 1 {
+		return c.parallelBestPath(ctx, m, s, maxLen)
 	}
-	w := NewWalker(m)
-	if c.randomness != BestPathRandomNone {
-		w.SetStepFilter(c.stepShuffler())
+	ps := c.newPathSearch(ctx, m, maxLen)
+	ps.search(s, 0)
+	if ps.best == nil {
+		return nil, nil, ps.err
 	}
-	for path := range w.IterPaths(s, maxLen) {
-		est := c.EstimateCoverageIncrease(path)
-		// only return paths that increase coverage
-		if est.MaxIncrease == 0 {
-			continue
-		}
-		if best == nil {
-			updateBest(path, est)
-			if c.randomness >= BestPathRandomAmongAnyPath {
-				break
-			}
-			continue
-		}
-		if est.MaxIncrease < best.MaxIncrease {
-			continue
-		}
-		if est.MaxIncrease > best.MaxIncrease {
-			updateBest(path, est)
-			continue
-		}
-		// if we are here, est.MaxIncrease == best.MaxIncrease
-		if c.randomness == BestPathRandomAmongMaxCoverageIncrease {
-			// we are free to take any path with the same max increase, never mind about other stats
-			continue
-		}
-		if est.MaxStep > best.MaxStep {
-			continue
-		}
-		if est.MaxStep < best.MaxStep {
-			updateBest(path, est)
-			continue
-		}
-		// if we are here, est.MaxStep == best.MaxStep
-		if c.randomness == BestPathRandomAmongFastestMaxCoverageIncrease {
-			// we are free to take any path that equally few steps to reach max increase, never mind about other stats
-			continue
-		}
-		if est.FirstStep > best.FirstStep {
-			continue
-		}
-		if est.FirstStep < best.FirstStep {
-			updateBest(path, est)
-			continue
-		}
-		// if we are here, est.FirstStep == best.FirstStep
-		if est.FirstIncrease < best.FirstIncrease {
-			continue
-		}
-		if est.FirstIncrease > best.FirstIncrease {
-			updateBest(path, est)
-			continue
+	return ps.bestPath, ps.best, ps.err
+}
+
+// isBetter returns true if est is strictly better than best,
+// considering the randomness level.
+func (c *Coverer) isBetter(est, best *CoverageIncreaseStats) bool {
+	if best == nil {
+		return true
+	}
+	if c.stepCost != nil {
+		if estPerCost, bestPerCost := increasePerCost(est), increasePerCost(best); estPerCost != bestPerCost {
+			return estPerCost > bestPerCost
 		}
-		// If we are here, est.FirstIncrease ==
-		// best.FirstIncrease that is, est and best paths are
-		// equally good. BestPathRandomAmongEquallyGood has
-		// been taken care of by shuffling paths in the
-		// beginning.
 	}
-	if best == nil {
-		return nil, nil
+	if est.MaxIncrease != best.MaxIncrease {
+		return est.MaxIncrease > best.MaxIncrease
+	}
+	if c.randomness == BestPathRandomAmongMaxCoverageIncrease {
+		// we are free to take any path with the same max increase, never mind about other stats
+		return false
+	}
+	if est.MaxStep != best.MaxStep {
+		return est.MaxStep < best.MaxStep
+	}
+	if c.randomness == BestPathRandomAmongFastestMaxCoverageIncrease {
+		// we are free to take any path that equally few steps to reach max increase, never mind about other stats
+		return false
+	}
+	if est.FirstStep != best.FirstStep {
+		return est.FirstStep < best.FirstStep
+	}
+	// If FirstIncreases are equal, est and best paths are equally
+	// good. BestPathRandomAmongEquallyGood has been taken care of
+	// by shuffling steps during the search.
+	return est.FirstIncrease > best.FirstIncrease
+}
+
+// increasePerCost returns the maximum coverage increase divided by
+// the cost of reaching it.
+func increasePerCost(est *CoverageIncreaseStats) float64 {
+	if est.MaxCost <= 0 {
+		return math.Inf(1)
 	}
-	return bestPath, best
+	return float64(est.MaxIncrease) / est.MaxCost
 }
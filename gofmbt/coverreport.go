@@ -0,0 +1,124 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"sort"
+)
+
+// FuncCoverage reports coverage of one coverage function, like
+// "states" or "action-combinations(2)".
+type FuncCoverage struct {
+	Name      string   // Name of the coverage function.
+	Covered   int      // Number of covered strings in the universe.
+	Total     int      // Number of strings in the universe.
+	Uncovered []string // Strings in the universe that are not covered.
+}
+
+// Percent returns covered strings as a percentage of the universe.
+func (fc *FuncCoverage) Percent() float64 {
+	if fc.Total == 0 {
+		return 100
+	}
+	return 100 * float64(fc.Covered) / float64(fc.Total)
+}
+
+// SetUniverse computes all strings that every coverage function can
+// cover in an explored state space. The universe enables reporting
+// coverage percentages and coverage goals. Call this after all
+// Cover*() calls. If the state space is truncated, the universe
+// contains only strings covered inside the explored state space.
+func (c *Coverer) SetUniverse(ss *StateSpace) {
+	w := NewWalker(ss)
+	for _, cf := range c.covFuncs {
+		cf.universe = map[string]bool{}
+		for _, s := range ss.Order {
+			// Every covered string depends on at most window
+			// steps before the step that covers it.
+			for path := range w.IterPaths(ss.States[s], cf.window+1) {
+				for _, covered := range cf.f(path) {
+					cf.universe[covered] = true
+				}
+			}
+		}
+	}
+}
+
+// CoverageReport returns coverage of every coverage function in the
+// universe set with SetUniverse(). Returns nil if universe has not
+// been set.
+func (c *Coverer) CoverageReport() []*FuncCoverage {
+	report := []*FuncCoverage{}
+	for _, cf := range c.covFuncs {
+		if cf.universe == nil {
+			return nil
+		}
+		fc := &FuncCoverage{Name: cf.name, Total: len(cf.universe), Uncovered: []string{}}
+		for s := range cf.universe {
+			if c.coverCount[s] > 0 {
+				fc.Covered++
+			} else {
+				fc.Uncovered = append(fc.Uncovered, s)
+			}
+		}
+		sort.Strings(fc.Uncovered)
+		report = append(report, fc)
+	}
+	return report
+}
+
+// SetCoverageGoal sets a coverage goal that is reached when coverage
+// of named coverage functions is at least percent of their
+// universe. If no names are given, the goal concerns all coverage
+// functions. For example, SetCoverageGoal(95, "state-actions"). A
+// name that does not match any coverage function makes the goal
+// unreachable.
+func (c *Coverer) SetCoverageGoal(percent float64, names ...string) {
+	c.goalPercent = percent
+	c.goalNames = names
+}
+
+// GoalReached returns true if the coverage goal has been reached. It
+// returns false if there is no goal, the universe has not been set,
+// or a name in the goal does not match any coverage function.
+func (c *Coverer) GoalReached() bool {
+	if c.goalPercent == 0 {
+		return false
+	}
+	report := c.CoverageReport()
+	if report == nil {
+		return false
+	}
+	inGoal := map[string]bool{}
+	for _, name := range c.goalNames {
+		inGoal[name] = true
+	}
+	matched := map[string]bool{}
+	for _, fc := range report {
+		if len(inGoal) > 0 && !inGoal[fc.Name] {
+			continue
+		}
+		if fc.Percent() < c.goalPercent {
+			return false
+		}
+		matched[fc.Name] = true
+	}
+	for name := range inGoal {
+		if !matched[name] {
+			return false
+		}
+	}
+	return true
+}
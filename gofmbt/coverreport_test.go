@@ -0,0 +1,100 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCoverageReport(t *testing.T) {
+	for modelName, model := range playerModels {
+		state := &PlayerState{false, 1}
+		coverer := NewCoverer()
+		coverer.CoverStates()
+		coverer.CoverStateActions()
+		coverer.CoverActionCombinations(2)
+		if coverer.CoverageReport() != nil {
+			t.Fatalf("model %q: expected no report without universe", modelName)
+		}
+		coverer.SetUniverse(model.Explore(state, ExploreLimits{}))
+		coverer.MarkCovered(model.StepsFrom(state)[0])
+		coverer.UpdateCoverage()
+		expected := map[string][2]int{
+			"states":                 {2, 6},
+			"state-actions":          {1, 14},
+			"action-combinations(2)": {1, 4 + 14},
+		}
+		for _, fc := range coverer.CoverageReport() {
+			t.Logf("%s: %d/%d (%.1f%%), uncovered: %q", fc.Name, fc.Covered, fc.Total, fc.Percent(), fc.Uncovered)
+			if exp := expected[fc.Name]; fc.Covered != exp[0] || fc.Total != exp[1] || len(fc.Uncovered) != exp[1]-exp[0] {
+				t.Fatalf("model %q: %s: expected %d/%d covered, got %d/%d", modelName, fc.Name, exp[0], exp[1], fc.Covered, fc.Total)
+			}
+		}
+
+		coverer.SetCoverageGoal(1, "state-action")
+		if coverer.GoalReached() {
+			t.Fatalf("model %q: goal with unknown name reached", modelName)
+		}
+		coverer.SetCoverageGoal(1, "states", "state-action")
+		if coverer.GoalReached() {
+			t.Fatalf("model %q: goal with partly unknown names reached", modelName)
+		}
+		coverer.SetCoverageGoal(1, "states")
+		if !coverer.GoalReached() {
+			t.Fatalf("model %q: expected goal reached", modelName)
+		}
+		coverer.SetCoverageGoal(50, "state-actions")
+		if coverer.GoalReached() {
+			t.Fatalf("model %q: goal reached too early", modelName)
+		}
+		result := NewRunner(model, &playerAdapter{playing: true, song: 1}, coverer).Run(context.Background(), model.StepsFrom(state)[0].EndState())
+		if result.Stop != RunStopCoverageTarget || !coverer.GoalReached() {
+			t.Fatalf("model %q: expected run to stop on coverage goal, got stop %d", modelName, result.Stop)
+		}
+		if fc := coverer.CoverageReport()[1]; fc.Covered != 7 {
+			t.Fatalf("model %q: expected 7/14 state-actions covered at the goal, got %d", modelName, fc.Covered)
+		}
+	}
+}
+
+func TestCoverageGoalOnOneFunction(t *testing.T) {
+	model := playerModels["when"]
+	initial := &PlayerState{false, 1}
+	coverer := NewCoverer()
+	coverer.CoverStates()
+	coverer.CoverActionCombinations(3)
+	coverer.SetUniverse(model.Explore(initial, ExploreLimits{}))
+	tours, err := TransitionTour(model, initial, ExploreLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tour := range tours {
+		coverer.MarkCovered(tour...)
+	}
+	coverer.UpdateCoverage()
+	report := coverer.CoverageReport()
+	if report[0].Percent() != 100 || report[1].Percent() >= 100 {
+		t.Fatalf("expected all states and not all action combinations covered, got %.1f%% and %.1f%%", report[0].Percent(), report[1].Percent())
+	}
+	coverer.SetCoverageGoal(100, "states")
+	if !coverer.GoalReached() {
+		t.Fatal("expected goal on states reached")
+	}
+	coverer.SetCoverageGoal(100, "states", "action-combinations(3)")
+	if coverer.GoalReached() {
+		t.Fatal("goal on action combinations reached too early")
+	}
+}
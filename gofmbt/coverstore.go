@@ -0,0 +1,93 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// coverageRecord is the serialized format of coverage.
+type coverageRecord struct {
+	Covered map[string]int `json:"covered"` // Covered strings and their counts.
+}
+
+// ExportCoverage writes covered strings and their counts in JSON.
+func (c *Coverer) ExportCoverage(w io.Writer) error {
+	return json.NewEncoder(w).Encode(&coverageRecord{Covered: c.coverCount})
+}
+
+// ImportCoverage reads coverage written by ExportCoverage and merges
+// it to current coverage. Imported coverage is kept over
+// UpdateCoverage() calls.
+func (c *Coverer) ImportCoverage(r io.Reader) error {
+	cr := &coverageRecord{}
+	if err := json.NewDecoder(r).Decode(cr); err != nil {
+		return err
+	}
+	c.addCoverCount(cr.Covered)
+	return nil
+}
+
+// SaveCoverage writes coverage to a file.
+func (c *Coverer) SaveCoverage(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := c.ExportCoverage(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadCoverage reads coverage from a file written by SaveCoverage and
+// merges it to current coverage.
+func (c *Coverer) LoadCoverage(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.ImportCoverage(f)
+}
+
+// MergeCoverage merges coverage of other Coverers to current
+// coverage. Only coverage of steps marked covered in other Coverers
+// is merged, not coverage that they have imported or merged. For
+// instance, if parallel runs loaded the same baseline coverage, load
+// the baseline once to this Coverer, too. Merged coverage is kept
+// over UpdateCoverage() calls.
+func (c *Coverer) MergeCoverage(others ...*Coverer) {
+	for _, other := range others {
+		own := map[string]int{}
+		for s, count := range other.coverCount {
+			if count > other.importCount[s] {
+				own[s] = count - other.importCount[s]
+			}
+		}
+		c.addCoverCount(own)
+	}
+}
+
+// addCoverCount adds imported or merged counts to current coverage.
+func (c *Coverer) addCoverCount(counts map[string]int) {
+	for s, count := range counts {
+		c.coverCount[s] += count
+		c.importCount[s] += count
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadMergeCoverage(t *testing.T) {
+	model := playerModels["when"]
+	state := &PlayerState{false, 1}
+	steps := model.StepsFrom(state)
+
+	first := NewCoverer()
+	first.CoverStateActions()
+	first.MarkCovered(steps[0])
+	first.UpdateCoverage()
+	filename := filepath.Join(t.TempDir(), "coverage.json")
+	if err := first.SaveCoverage(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewCoverer()
+	second.CoverStateActions()
+	if err := second.LoadCoverage(filename); err != nil {
+		t.Fatal(err)
+	}
+	if second.Coverage() != 1 {
+		t.Fatalf("expected coverage 1 after loading, got %d", second.Coverage())
+	}
+	second.MarkCovered(steps[1])
+	second.UpdateCoverage()
+	if second.Coverage() != 2 {
+		t.Fatalf("expected loaded coverage to be kept, got coverage %d", second.Coverage())
+	}
+	path, _ := second.BestPath(model, state, 1)
+	if path != nil {
+		t.Fatalf("expected no coverage increase with one step, got %v", path)
+	}
+
+	// Coverage that second loaded from first is not merged twice.
+	merged := NewCoverer()
+	merged.CoverStateActions()
+	merged.MergeCoverage(first, second)
+	if merged.Coverage() != 2 || merged.coverCount[StateActionStrings(steps[:1])[0]] != 1 {
+		t.Fatalf("expected 2 covered strings, both once, got %v", merged.coverCount)
+	}
+
+	// Merged coverage is not merged again either.
+	again := NewCoverer()
+	again.CoverStateActions()
+	again.MergeCoverage(merged, second)
+	if again.Coverage() != 1 || again.coverCount[StateActionStrings(steps[1:2])[0]] != 1 {
+		t.Fatalf("expected only the step covered by second, got %v", again.coverCount)
+	}
+}
@@ -80,6 +80,19 @@
 // that the generator may return. Model.From() can be called multiple
 // times to add multiple transition generator functions.
 //
+// Compose() combines Models of components, each with its own State
+// type and initial state, into a product Model whose State is a
+// ProductState. Actions of components are interleaved, except
+// actions that are shared by several components: they are
+// synchronized.
+//
+// SubModel embeds a Model inside states of parent Models. For
+// instance, a settings menu can be modeled once and entered from
+// many states of an application model with a transition whose
+// StateChange is SubModel.Enter(). The sub-model returns to the
+// parent state with ExitSubModel(). Coverer.CoverSubModel() reports
+// coverage of each sub-model separately.
+//
 // Refer to model_test.go to find examples of defining the same model
 // for a player in two different ways: first with StateChanges and
 // Transitions, and then with convenience functions When/OnAction/Do.
@@ -95,6 +108,24 @@
 // all possible Paths of at most maxLen Steps where the first Step of
 // every Path starts from the State.
 //
+// Walker.SetStrategy() selects how a Walker enumerates Paths:
+// depth-first (default), breadth-first, iterative deepening, or a
+// seeded random walk that yields a single long Path, which is the
+// cheapest way to generate long soak tests from a large Model.
+// Action.WithWeight() sets the relative probability of choosing an
+// Action in random walks and in randomized Coverer.BestPath() search,
+// which lets soak tests follow an expected usage profile.
+//
+// Walker.SetPruning() stops extending Paths from States that have
+// already been extended in the same enumeration, which avoids
+// enumerating identical suffixes after different prefixes.
+//
+// Walker.ShortestPath() finds a Path with the fewest Steps to any
+// State that satisfies a predicate, for instance to drive the system
+// under test to a wanted configuration before a scenario.
+// Walker.CheapestPath() does the same with given costs of Steps, or
+// with costs of Actions set with Action.WithCost().
+//
 // Coverer helps finding Paths that increase coverage of wanted
 // elements. Elements to be covered are specified by Coverer methods:
 //  - CoverStates(): cover unique State.String()s:
@@ -109,6 +140,14 @@
 //    test all action-paths of length n.
 //  - CoverActionFormats(): unique Action formats:
 //    test every action format, ignoring action parameters.
+//  - CoverTags(): unique tags set with Action.WithTags():
+//    test every feature area or requirement.
+//  - CoverTagCombinations(n): unique Tag_1, ..., Tag_n combinations:
+//    test feature areas in sequence, Tag_i being a tag of Action_i.
+//  - CoverSubModel(SubModel): unique state-action pairs inside a SubModel:
+//    test every action in every state of a sub-model.
+//  - Cover(name, window, CoveredInPath): custom coverage function
+//    whose covered strings depend on at most window previous Steps.
 //
 // Calling multiple Cover*() functions allows specifying multiple
 // elements whose coverage counts. For example, CoverActions() and
@@ -124,17 +163,40 @@
 // Cover.BestPath(Model, State, maxLen) returns a Path, starting
 // from a State in a Model, that results in largest increase in
 // whatever elements are covered. The Path is nil if coverage cannot
-// be increased by any Path of at most maxLen Steps.
+// be increased by any Path of at most maxLen Steps. With
+// Coverer.SetBestPathWorkers(n) the search runs on n goroutines.
+// Coverer.SetBestPathCost(ActionCost) makes BestPath() maximize
+// coverage increase per cost instead, which avoids expensive Actions
+// like reboots unless they are worth it. CoverageIncreaseStats
+// reports the cost of the Path.
+// Coverer.BestPathContext() and Walker.IterPathsContext() stop when
+// a context is cancelled, which allows setting time budgets with
+// context.WithTimeout().
 //
 // When one or more Steps in a Path have been handled, they are marked
 // as covered by calling Coverer.MarkCovered(Step...). Having all
-// marked, Coverer.UpdateCoverage() must be called. This can be heavy
-// operation, depending on how coverage is measured and how many steps
-// have been covered, and therefore it is done separately from marking
-// individual Steps covered. Once updated, Coverer.Coverage() returns
-// the total number of elements that have been covered by in all
-// marked Steps, and Coverer.BestPath() will use new coverage as basis
-// when searching for new BestPaths().
+// marked, Coverer.UpdateCoverage() must be called. Updating is
+// incremental: only Steps marked after the previous update are
+// processed, together with a short window of history Steps, so its
+// cost does not grow with the length of the test. Once updated,
+// Coverer.Coverage() returns the total number of elements that have
+// been covered by in all marked Steps, and Coverer.BestPath() will
+// use new coverage as basis when searching for new BestPaths().
+//
+// Coverer.Coverage() is an absolute number. Coverer.SetUniverse()
+// computes all elements that can be covered in an explored
+// StateSpace. After that Coverer.CoverageReport() returns coverage
+// percentage and uncovered elements of every Cover*() function, and
+// Coverer.SetCoverageGoal(95, "state-actions") sets a goal that stops
+// Runner when 95 % of state-action pairs are covered.
+//
+// Coverer.TagReport() maps every tag to covered Steps whose Actions
+// have the tag, for instance to trace requirements to tests.
+//
+// Coverage can be saved to a file with Coverer.SaveCoverage() and
+// loaded with Coverer.LoadCoverage(), so that test generation can
+// continue from where a previous run left off. Coverer.MergeCoverage()
+// combines coverage of parallel runs.
 //
 // Test generation loop example:
 //
@@ -157,5 +219,70 @@
 //          }
 //          state = path[stats.FirstStep].EndState()
 //  }
+//
+// TransitionTour() generates tests offline: it explores the Model and
+// returns a near-minimal walk that covers every state-action pair,
+// that is, a Chinese postman tour. If some states cannot be reached
+// from others, the result is a set of walks that start from the
+// initial state. The Model must be deterministic.
+//
+// WMethod() generates a conformance test suite for a deterministic
+// finite Model with the W-method. Observable outputs of Actions are
+// set with Action.WithOutput(). The suite combines a transition
+// cover with sequences that distinguish states by their outputs,
+// and gives stronger fault detection guarantees than state-action
+// coverage.
+//
+// Model.SetInitialState() and Model.SetResetAction() declare where
+// test cases start and how the system under test returns there.
+// GenerateSuite() generates a set of independent test cases, each
+// starting from the initial state, that together reach a coverage
+// goal. Independent test cases can be sharded to parallel test
+// runners, or joined with reset steps by Model.JoinSuite().
+//
+// # Online testing
+//
+// Runner implements the test generation loop above and executes
+// every Step on the system under test through an Adapter, that is
+// implemented by user. Run() stops when coverage cannot be increased,
+// a Step fails, or a step, time or coverage limit is reached. It
+// returns a RunResult with the executed trace and failure details.
+// If the Adapter implements Observer, observations after every Step
+// are recorded, too. If the Adapter implements Verifier, the state of
+// the system under test is compared to the expected end State after
+// every Step, and a divergence stops the run at the Step where it
+// happened.
+//
+//  runner := NewRunner(model, adapter, coverer)
+//  runner.SetTimeBudget(10 * time.Minute)
+//  result := runner.Run(context.Background(), state)
+//
+// Shrink() searches for a shorter version of a failing Path that
+// still fails on the system under test. NewPathRecord() saves a Path
+// in JSON or text format, and Replay() validates a saved Path against
+// a Model, which allows keeping failing sequences as regression
+// tests.
+//
+// # Model analysis
+//
+// Model.Explore(State, ExploreLimits) explores all states reachable
+// from a State breadth-first, identifying states by State.String().
+// The resulting StateSpace contains reachable states and Steps
+// between them, and shortest paths to every state. WriteDot() writes
+// the explored state space as a Graphviz DOT digraph.
+//
+// CheckDeadEnds() reports states where the model has no Steps, that
+// is, where generated tests would end unexpectedly. It fits in a
+// test as a one-line assertion:
+//
+//  if err := CheckDeadEnds(model, state, ExploreLimits{}, nil); err != nil {
+//          t.Fatal(err)
+//  }
+//
+// CheckDeterminism() reports states where the same Action leads to
+// different end states. Such a model is ambiguous for online testing,
+// because the tester cannot know which end state to expect. In strict
+// mode, set with Model.SetStrict(true), Model.StepsFrom() panics on
+// nondeterministic Steps.
 
 package gofmbt
@@ -0,0 +1,96 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DotOptions specifies how a state space is written as a Graphviz
+// DOT digraph.
+type DotOptions struct {
+	Coverer          *Coverer // Highlight steps covered by this Coverer, if set.
+	CollapseParallel bool     // Write all steps between two states as one edge.
+	MaxStates        int      // Maximum number of states to explore, 0 is unlimited.
+}
+
+// WriteDot explores a model from an initial state and writes the
+// explored state space as a DOT digraph. States are nodes labelled
+// by State.String() and Steps are edges labelled by
+// Action.String(). Options can be nil.
+func WriteDot(w io.Writer, m Walkable, initial State, opts *DotOptions) error {
+	if opts == nil {
+		opts = &DotOptions{}
+	}
+	ss := Explore(m, initial, ExploreLimits{MaxStates: opts.MaxStates})
+	covered := map[string]bool{}
+	if opts.Coverer != nil {
+		for _, step := range opts.Coverer.coveredPath {
+			covered[dotStepKey(step)] = true
+		}
+	}
+	nodeId := map[string]string{}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "digraph {\n")
+	for i, s := range ss.Order {
+		nodeId[s] = fmt.Sprintf("s%d", i)
+		attrs := ""
+		if i == 0 {
+			attrs = ", peripheries=2"
+		}
+		fmt.Fprintf(sb, "  %s [label=%s%s];\n", nodeId[s], dotQuote(s), attrs)
+	}
+	// Group steps to edges. Without collapsing every step is an edge.
+	type edge struct {
+		start, end string
+		labels     []string
+		covered    bool
+	}
+	edges := []*edge{}
+	edgeOf := map[string]*edge{}
+	for _, step := range ss.Steps {
+		start, end := step.start.String(), step.end.String()
+		e := edgeOf[start+"\x00"+end]
+		if e == nil || !opts.CollapseParallel {
+			e = &edge{start: start, end: end}
+			edges = append(edges, e)
+			edgeOf[start+"\x00"+end] = e
+		}
+		e.labels = append(e.labels, step.action.String())
+		e.covered = e.covered || covered[dotStepKey(step)]
+	}
+	for _, e := range edges {
+		attrs := ""
+		if e.covered {
+			attrs = ", color=blue, penwidth=2"
+		}
+		fmt.Fprintf(sb, "  %s -> %s [label=%s%s];\n", nodeId[e.start], nodeId[e.end], dotQuote(strings.Join(e.labels, "\n")), attrs)
+	}
+	fmt.Fprintf(sb, "}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func dotStepKey(step *Step) string {
+	return step.start.String() + "\x00" + step.action.String() + "\x00" + step.end.String()
+}
+
+// dotQuote returns s as a DOT string literal.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\x00", `\\0`)
+	return `"` + r.Replace(s) + `"`
+}
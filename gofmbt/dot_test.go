@@ -0,0 +1,50 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDot(t *testing.T) {
+	model := playerModels["when"]
+	state := &PlayerState{false, 1}
+	coverer := NewCoverer()
+	coverer.MarkCovered(model.StepsFrom(state)...)
+	sb := &strings.Builder{}
+	if err := WriteDot(sb, model, state, &DotOptions{Coverer: coverer}); err != nil {
+		t.Fatal(err)
+	}
+	dot := sb.String()
+	t.Log(dot)
+	if n := strings.Count(dot, " -> "); n != 14 {
+		t.Fatalf("expected 14 edges, got %d", n)
+	}
+	if n := strings.Count(dot, "color=blue"); n != 2 {
+		t.Fatalf("expected 2 highlighted edges, got %d", n)
+	}
+	if !strings.Contains(dot, `s0 [label="{playing:false,song:1}", peripheries=2]`) {
+		t.Fatalf("initial state node missing")
+	}
+
+	sb.Reset()
+	if err := WriteDot(sb, model, state, &DotOptions{MaxStates: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(sb.String(), "[label="); n != 4 {
+		t.Fatalf("expected 2 nodes and 2 edges, got %d labels in:\n%s", n, sb.String())
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+// ExploreLimits limits state space exploration. Zero value of a limit
+// means unlimited.
+type ExploreLimits struct {
+	MaxStates int // Maximum number of states to be explored.
+	MaxDepth  int // Maximum number of steps from the initial state.
+}
+
+// StateSpace is a labelled transition system that has been explored
+// from an initial state. States are identified by State.String().
+type StateSpace struct {
+	Initial   State            // Initial state of the exploration.
+	States    map[string]State // Reachable states.
+	Order     []string         // Reachable states in the order they were found.
+	Steps     []*Step          // Steps between reachable states.
+	Truncated bool             // True if exploration was stopped by a limit.
+	depth     map[string]int   // Number of steps needed to reach a state.
+	parent    map[string]*Step // Last step of a shortest path to a state.
+	out       map[string][]*Step
+	expanded  map[string]bool // States whose outgoing steps were explored.
+	deadEnd   map[string]bool // Expanded states without any outgoing steps.
+}
+
+// Explore explores the state space of a model breadth-first from an
+// initial state.
+func (m *Model) Explore(initial State, limits ExploreLimits) *StateSpace {
+	return Explore(m, initial, limits)
+}
+
+// Explore explores the state space of any walkable model
+// breadth-first from an initial state.
+func Explore(m Walkable, initial State, limits ExploreLimits) *StateSpace {
+	initialStr := initial.String()
+	ss := &StateSpace{
+		Initial:  initial,
+		States:   map[string]State{initialStr: initial},
+		Order:    []string{initialStr},
+		depth:    map[string]int{initialStr: 0},
+		parent:   map[string]*Step{},
+		out:      map[string][]*Step{},
+		expanded: map[string]bool{},
+		deadEnd:  map[string]bool{},
+	}
+	for next := 0; next < len(ss.Order); next++ {
+		startStr := ss.Order[next]
+		start := ss.States[startStr]
+		steps := m.StepsFrom(start)
+		if limits.MaxDepth > 0 && ss.depth[startStr] >= limits.MaxDepth {
+			if len(steps) > 0 {
+				ss.Truncated = true
+			}
+			continue
+		}
+		ss.expanded[startStr] = true
+		if len(steps) == 0 {
+			ss.deadEnd[startStr] = true
+		}
+		for _, step := range steps {
+			endStr := step.end.String()
+			if _, ok := ss.States[endStr]; !ok {
+				if limits.MaxStates > 0 && len(ss.Order) >= limits.MaxStates {
+					ss.Truncated = true
+					continue
+				}
+				ss.States[endStr] = step.end
+				ss.Order = append(ss.Order, endStr)
+				ss.depth[endStr] = ss.depth[startStr] + 1
+				ss.parent[endStr] = step
+			}
+			ss.Steps = append(ss.Steps, step)
+			ss.out[startStr] = append(ss.out[startStr], step)
+		}
+	}
+	return ss
+}
+
+// StepsFrom returns explored steps that start from a given
+// state. This makes StateSpace Walkable.
+func (ss *StateSpace) StepsFrom(s State) []*Step {
+	return ss.out[s.String()]
+}
+
+// Depth returns the number of steps in a shortest path from the
+// initial state to a state, or -1 if the state has not been reached.
+func (ss *StateSpace) Depth(s State) int {
+	if depth, ok := ss.depth[s.String()]; ok {
+		return depth
+	}
+	return -1
+}
+
+// PathTo returns a shortest path from the initial state to a
+// state. Returns nil if the state has not been reached or if it is
+// the initial state.
+func (ss *StateSpace) PathTo(s State) Path {
+	last := ss.parent[s.String()]
+	if last == nil {
+		return nil
+	}
+	return pathFromParents(ss.parent, last)
+}
@@ -0,0 +1,51 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"testing"
+)
+
+func TestExplore(t *testing.T) {
+	for modelName, model := range playerModels {
+		ss := model.Explore(&PlayerState{false, 1}, ExploreLimits{})
+		if ss.Truncated {
+			t.Fatalf("model %q: unexpected truncation", modelName)
+		}
+		if len(ss.States) != 6 {
+			t.Fatalf("model %q: expected 6 states, got %d: %v", modelName, len(ss.States), ss.Order)
+		}
+		if len(ss.Steps) != 14 {
+			t.Fatalf("model %q: expected 14 steps, got %d", modelName, len(ss.Steps))
+		}
+		target := &PlayerState{true, 3}
+		path := ss.PathTo(target)
+		if len(path) != 3 || ss.Depth(target) != 3 {
+			t.Fatalf("model %q: expected shortest path of 3 steps to %s, got %v", modelName, target, path)
+		}
+		if path[len(path)-1].EndState().String() != target.String() {
+			t.Fatalf("model %q: path %v does not end to %s", modelName, path, target)
+		}
+
+		ss = model.Explore(&PlayerState{false, 1}, ExploreLimits{MaxDepth: 1})
+		if !ss.Truncated || len(ss.States) != 3 {
+			t.Fatalf("model %q: expected 3 states and truncation with MaxDepth 1, got %d states, truncated: %v", modelName, len(ss.States), ss.Truncated)
+		}
+		ss = model.Explore(&PlayerState{false, 1}, ExploreLimits{MaxStates: 4})
+		if !ss.Truncated || len(ss.States) != 4 {
+			t.Fatalf("model %q: expected 4 states and truncation with MaxStates 4, got %d states, truncated: %v", modelName, len(ss.States), ss.Truncated)
+		}
+	}
+}
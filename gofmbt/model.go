@@ -14,6 +14,10 @@
 
 package gofmbt
 
+import (
+	"fmt"
+)
+
 // Walkable models can be traversed step-by-step from state to state.
 type Walkable interface {
 	// StepsFrom returns all alternative steps that start from a
@@ -23,7 +27,10 @@ type Walkable interface {
 
 // Model specifies a state space.
 type Model struct {
-	gen []TransitionGen // transition generators
+	gen     []TransitionGen // transition generators
+	strict  bool            // panic on nondeterministic steps
+	initial State           // initial state of test cases
+	reset   *Action         // action that returns to the initial state
 }
 
 // NewModel creates a new model.
@@ -36,8 +43,13 @@ func (m *Model) From(transitionGen func(State) []*Transition) {
 	m.gen = append(m.gen, transitionGen)
 }
 
-// TransitionsFrom returns all transitions that may be taken from a given state.
+// TransitionsFrom returns all transitions that may be taken from a given
+// state. In a state of a sub-model, only transitions of the sub-model
+// may be taken.
 func (m *Model) TransitionsFrom(s State) []*Transition {
+	if ss, ok := s.(*SubModelState); ok {
+		return ss.sub.transitions(ss)
+	}
 	ts := []*Transition{}
 	for _, gen := range m.gen {
 		ts = append(ts, gen(s)...)
@@ -45,6 +57,43 @@ func (m *Model) TransitionsFrom(s State) []*Transition {
 	return ts
 }
 
+// SetInitialState sets the state where every test case starts.
+func (m *Model) SetInitialState(s State) {
+	m.initial = s
+}
+
+// InitialState returns the initial state, or nil if it has not been set.
+func (m *Model) InitialState() State {
+	return m.initial
+}
+
+// SetResetAction sets the action that returns the system under test
+// from any state to the initial state.
+func (m *Model) SetResetAction(a *Action) {
+	m.reset = a
+}
+
+// ResetAction returns the reset action, or nil if it has not been set.
+func (m *Model) ResetAction() *Action {
+	return m.reset
+}
+
+// ResetStep returns a step with the reset action from a state to the
+// initial state, or nil if the model has no reset action or initial
+// state.
+func (m *Model) ResetStep(s State) *Step {
+	if m.reset == nil || m.initial == nil {
+		return nil
+	}
+	return NewStep(s, m.reset, m.initial)
+}
+
+// SetStrict sets the strict mode of the model. In strict mode
+// StepsFrom panics if the same action leads to different end states.
+func (m *Model) SetStrict(strict bool) {
+	m.strict = strict
+}
+
 // Steps returns all steps that start from a given state.
 func (m *Model) StepsFrom(s State) []*Step {
 	steps := []*Step{}
@@ -53,5 +102,10 @@ func (m *Model) StepsFrom(s State) []*Step {
 			steps = append(steps, NewStep(s, t.action, endState))
 		}
 	}
+	if m.strict {
+		if nds := findNondeterminism(steps); len(nds) > 0 {
+			panic(fmt.Sprintf("gofmbt: nondeterministic action %q in state %s leads to %v", nds[0].Action, s, nds[0].EndStates))
+		}
+	}
 	return steps
 }
@@ -234,6 +234,45 @@ func TestSearchPathsTestSteps(t *testing.T) {
 	}
 }
 
+func TestIncrementalUpdateCoverage(t *testing.T) {
+	model := playerModels["when"]
+	path := NewWalker(model).Paths(&PlayerState{false, 1}, 9)[40]
+	newCoverer := func() *Coverer {
+		c := NewCoverer()
+		c.CoverStates()
+		c.CoverStateActions()
+		c.CoverActionCombinations(3)
+		c.CoverStateCombinations(2)
+		c.Cover("actions-twice", 1, func(path Path) []string {
+			twice := []string{}
+			for i := 1; i < len(path); i++ {
+				if path[i].Action().String() == path[i-1].Action().String() {
+					twice = append(twice, path[i].Action().String())
+				}
+			}
+			return twice
+		})
+		return c
+	}
+	full := map[string]int{}
+	for _, s := range newCoverer().covFunc(path) {
+		full[s]++
+	}
+	incremental := newCoverer()
+	for first := 0; first < len(path); first += 2 {
+		incremental.MarkCovered(path[first:min(first+2, len(path))]...)
+		incremental.UpdateCoverage()
+	}
+	if len(full) != len(incremental.coverCount) {
+		t.Fatalf("expected %d covered strings, got %d", len(full), len(incremental.coverCount))
+	}
+	for s, count := range full {
+		if incremental.coverCount[s] != count {
+			t.Fatalf("expected %q covered %d times, got %d", s, count, incremental.coverCount[s])
+		}
+	}
+}
+
 type MyState string
 
 func (ms MyState) String() string {
@@ -278,6 +317,83 @@ func TestPaths(t *testing.T) {
 	}
 }
 
+func TestWalkStrategies(t *testing.T) {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("a").Do(gotoMyState("A"))),
+			When(ms == "start", OnAction("b").Do(gotoMyState("B"))),
+			When(ms == "B", OnAction("c").Do(gotoMyState("start"))),
+		)
+	})
+	for _, strategy := range []int{WalkBreadthFirst, WalkIterativeDeepening} {
+		w := NewWalker(model)
+		w.SetStrategy(strategy)
+		traces := []string{}
+		for _, path := range w.Paths(MyState("start"), 4) {
+			traces = append(traces, strings.Join(ActionNames(path), ""))
+		}
+		if expected := "a b bc bca bcb bcbc"; strings.Join(traces, " ") != expected {
+			t.Fatalf("strategy %d: expected traces %q, got %q", strategy, expected, traces)
+		}
+	}
+
+	w := NewWalker(playerModels["when"])
+	w.SetStrategy(WalkRandom)
+	w.SetRandomSeed(42)
+	paths := w.Paths(&PlayerState{false, 1}, 100)
+	if len(paths) != 1 || len(paths[0]) != 100 {
+		t.Fatalf("expected one random path of 100 steps, got %d paths", len(paths))
+	}
+	for i := 1; i < len(paths[0]); i++ {
+		if paths[0][i].StartState().String() != paths[0][i-1].EndState().String() {
+			t.Fatalf("invalid random path at step %d: %v", i, paths[0])
+		}
+	}
+	w.SetRandomSeed(42)
+	if again := w.Paths(&PlayerState{false, 1}, 100); strings.Join(ActionNames(again[0]), " ") != strings.Join(ActionNames(paths[0]), " ") {
+		t.Fatalf("expected the same random path with the same seed")
+	}
+}
+
+func TestWalkPruning(t *testing.T) {
+	model := playerModels["when"]
+	state := &PlayerState{false, 1}
+	stateActions := func(paths []Path) map[string]bool {
+		covered := map[string]bool{}
+		for _, path := range paths {
+			for _, s := range StateActionStrings(path) {
+				covered[s] = true
+			}
+		}
+		return covered
+	}
+	pathCount := map[int]int{}
+	for _, pruning := range []int{PruneNone, PruneStateDepths, PruneStates} {
+		w := NewWalker(model)
+		w.SetPruning(pruning)
+		paths := w.Paths(state, 6)
+		pathCount[pruning] = len(paths)
+		if covered := stateActions(paths); len(covered) != 14 {
+			t.Fatalf("pruning %d: expected all 14 state-actions in paths, got %d", pruning, len(covered))
+		}
+	}
+	if !(pathCount[PruneNone] > pathCount[PruneStateDepths] && pathCount[PruneStateDepths] > pathCount[PruneStates]) {
+		t.Fatalf("expected fewer paths with more pruning, got %v", pathCount)
+	}
+	t.Log("paths with pruning:", pathCount)
+
+	// Pruning state depths must not lose any suffix: all states
+	// reachable within maxLen steps are still reachable.
+	w := NewWalker(model)
+	w.SetPruning(PruneStateDepths)
+	w.SetStrategy(WalkIterativeDeepening)
+	if covered := stateActions(w.Paths(state, 6)); len(covered) != 14 {
+		t.Fatalf("iterative deepening: expected 14 state-actions, got %d", len(covered))
+	}
+}
+
 func BenchmarkPathsSmallStateSpace(b *testing.B) {
 	for modelName, model := range playerModels {
 		state := &PlayerState{false, 1}
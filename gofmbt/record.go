@@ -0,0 +1,167 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StepRecord is a serializable record of a Step.
+type StepRecord struct {
+	Start  string        `json:"start"`          // Start state String().
+	Action string        `json:"action"`         // Action String().
+	Format string        `json:"format"`         // Action format.
+	Args   []interface{} `json:"args,omitempty"` // Action arguments.
+	End    string        `json:"end"`            // End state String().
+}
+
+// PathRecord is a serializable record of a Path.
+type PathRecord struct {
+	Steps []*StepRecord `json:"steps"`
+}
+
+// NewPathRecord creates a serializable record of a path.
+func NewPathRecord(path Path) *PathRecord {
+	pr := &PathRecord{Steps: make([]*StepRecord, 0, len(path))}
+	for _, step := range path {
+		pr.Steps = append(pr.Steps, &StepRecord{
+			Start:  step.start.String(),
+			Action: step.action.name,
+			Format: step.action.format,
+			Args:   step.action.args,
+			End:    step.end.String(),
+		})
+	}
+	return pr
+}
+
+// WriteJSON writes the path record in JSON.
+func (pr *PathRecord) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(pr)
+}
+
+// ReadPathJSON reads a path record written by WriteJSON.
+func ReadPathJSON(r io.Reader) (*PathRecord, error) {
+	pr := &PathRecord{}
+	if err := json.NewDecoder(r).Decode(pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// WriteText writes the path record in line-oriented text format.
+// Every line contains a step as quoted strings: start state, action,
+// action format, action arguments as a JSON array, and end state.
+func (pr *PathRecord) WriteText(w io.Writer) error {
+	for _, sr := range pr.Steps {
+		args, err := json.Marshal(sr.Args)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%q %q %q %q %q\n", sr.Start, sr.Action, sr.Format, args, sr.End); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadPathText reads a path record written by WriteText. Empty lines
+// and lines starting with # are ignored. Lines with only start state,
+// action and end state are accepted, too.
+func ReadPathText(r io.Reader) (*PathRecord, error) {
+	pr := &PathRecord{Steps: []*StepRecord{}}
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := []string{}
+		for len(line) > 0 && len(fields) < 5 {
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			field, _ := strconv.Unquote(quoted)
+			fields = append(fields, field)
+			line = strings.TrimSpace(line[len(quoted):])
+		}
+		if line != "" || (len(fields) != 3 && len(fields) != 5) {
+			return nil, fmt.Errorf("line %d: expected three quoted strings (start, action, end) or five (start, action, format, args, end)", lineNum)
+		}
+		sr := &StepRecord{Start: fields[0], Action: fields[1], End: fields[len(fields)-1]}
+		if len(fields) == 5 {
+			sr.Format = fields[2]
+			if err := json.Unmarshal([]byte(fields[3]), &sr.Args); err != nil {
+				return nil, fmt.Errorf("line %d: args: %w", lineNum, err)
+			}
+			if len(sr.Args) == 0 {
+				sr.Args = nil
+			}
+		}
+		pr.Steps = append(pr.Steps, sr)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// ReplayError reports the first recorded step that is not possible
+// in a model.
+type ReplayError struct {
+	Index  int         // Index of the step in the record.
+	Step   *StepRecord // The step that is not possible.
+	Reason string      // Why the step is not possible.
+}
+
+// Error returns the step that is not possible and why.
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("step %d %q from %q: %s", e.Index, e.Step.Action, e.Step.Start, e.Reason)
+}
+
+// Replay validates a path record against a model, step by step,
+// starting from an initial state. Returns the path in the model, or
+// the valid prefix of the path and a *ReplayError if a step is no
+// longer possible.
+func Replay(m Walkable, initial State, pr *PathRecord) (Path, error) {
+	path := make(Path, 0, len(pr.Steps))
+	state := initial
+	for i, sr := range pr.Steps {
+		replayErr := func(format string, args ...interface{}) error {
+			return &ReplayError{Index: i, Step: sr, Reason: fmt.Sprintf(format, args...)}
+		}
+		if state.String() != sr.Start {
+			return path, replayErr("model is in state %q", state)
+		}
+		step := matchingStep(m.StepsFrom(state), sr.Action, sr.End)
+		if step == nil {
+			return path, replayErr("action is not possible")
+		}
+		if step.end.String() != sr.End {
+			return path, replayErr("action leads to %q instead of %q", step.end, sr.End)
+		}
+		path = append(path, step)
+		state = step.end
+	}
+	return path, nil
+}
@@ -0,0 +1,93 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	for modelName, model := range playerModels {
+		initial := &PlayerState{false, 1}
+		coverer := NewCoverer()
+		coverer.CoverStates()
+		path, _ := coverer.BestPath(model, initial, 6)
+		pr := NewPathRecord(path)
+
+		readPrs := []*PathRecord{}
+		for _, format := range []struct {
+			write func(*PathRecord, *bytes.Buffer) error
+			read  func(*bytes.Buffer) (*PathRecord, error)
+		}{
+			{func(pr *PathRecord, b *bytes.Buffer) error { return pr.WriteJSON(b) }, func(b *bytes.Buffer) (*PathRecord, error) { return ReadPathJSON(b) }},
+			{func(pr *PathRecord, b *bytes.Buffer) error { return pr.WriteText(b) }, func(b *bytes.Buffer) (*PathRecord, error) { return ReadPathText(b) }},
+		} {
+			buf := &bytes.Buffer{}
+			if err := format.write(pr, buf); err != nil {
+				t.Fatalf("model %q: write failed: %s", modelName, err)
+			}
+			t.Log(buf.String())
+			readPr, err := format.read(buf)
+			if err != nil {
+				t.Fatalf("model %q: read failed: %s", modelName, err)
+			}
+			readPrs = append(readPrs, readPr)
+			replayed, err := Replay(model, initial, readPr)
+			if err != nil {
+				t.Fatalf("model %q: replay failed: %s", modelName, err)
+			}
+			if len(replayed) != len(path) {
+				t.Fatalf("model %q: expected %d replayed steps, got %d", modelName, len(path), len(replayed))
+			}
+			for i := range path {
+				if replayed[i].String() != path[i].String() {
+					t.Fatalf("model %q: step %d: expected %s, got %s", modelName, i, path[i], replayed[i])
+				}
+			}
+		}
+		// JSON and text formats record the same data.
+		if !reflect.DeepEqual(readPrs[0], readPrs[1]) {
+			t.Fatalf("model %q: JSON and text records differ: %+v, %+v", modelName, readPrs[0].Steps, readPrs[1].Steps)
+		}
+		for i, sr := range readPrs[1].Steps {
+			if sr.Format != path[i].action.format || fmt.Sprint(sr.Args...) != fmt.Sprint(path[i].action.args...) {
+				t.Fatalf("model %q: step %d: expected format %q args %v, got %q %v", modelName, i, path[i].action.format, path[i].action.args, sr.Format, sr.Args)
+			}
+		}
+
+		pr.Steps[2].Action = "stop"
+		replayed, err := Replay(model, initial, pr)
+		if replayErr, ok := err.(*ReplayError); !ok || replayErr.Index != 2 || len(replayed) != 2 {
+			t.Fatalf("model %q: expected replay to fail on step 2, got %v", modelName, err)
+		}
+	}
+}
+
+func TestReadPathTextWithoutArgs(t *testing.T) {
+	pr, err := ReadPathText(strings.NewReader("# old format\n\"a\" \"go %d\" \"b\"\n\"b\" \"go %d\" \"go %d\" \"[2]\" \"c\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pr.Steps) != 2 || pr.Steps[0].End != "b" || pr.Steps[1].Format != "go %d" || len(pr.Steps[1].Args) != 1 || pr.Steps[1].End != "c" {
+		t.Fatalf("unexpected record %+v %+v", pr.Steps[0], pr.Steps[1])
+	}
+	if _, err := ReadPathText(strings.NewReader("\"a\" \"go\" \"go\" \"b\"\n")); err == nil {
+		t.Fatal("expected error on four fields")
+	}
+}
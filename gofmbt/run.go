@@ -0,0 +1,172 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	RunStopNoCoverageIncrease = iota // No path increases coverage anymore.
+	RunStopCoverageTarget            // Coverage target or goal has been reached.
+	RunStopMaxSteps                  // Maximum number of steps has been executed.
+	RunStopTimeout                   // Time budget exceeded or context done.
+	RunStopFailure                   // Executing a step failed.
+	RunStopMismatch                  // State of the system under test differs from the model.
+)
+
+// Adapter executes actions on the system under test.
+type Adapter interface {
+	// Execute executes an action on the system under test. It
+	// returns an error if the execution failed.
+	Execute(ctx context.Context, a *Action) error
+}
+
+// Observer can be optionally implemented by an Adapter. If
+// implemented, Runner calls Observe after every successfully
+// executed step and stores the observation in the result.
+type Observer interface {
+	Observe(ctx context.Context) (string, error)
+}
+
+// Verifier can be optionally implemented by an Adapter. If
+// implemented, Runner calls Verify after every successfully executed
+// step with the end state expected by the model. Verify returns a
+// description of the mismatch if the state of the system under test
+// differs from the expected state, otherwise an empty string.
+type Verifier interface {
+	Verify(ctx context.Context, expected State) string
+}
+
+// RunResult holds the outcome of a test run.
+type RunResult struct {
+	Stop         int      // Reason why the run stopped, one of RunStop* constants.
+	Trace        Path     // Executed steps. If a step failed, it is the last one.
+	Observations []string // Observations after each successful step if Adapter is an Observer.
+	FailedStep   *Step    // Step that failed, or nil.
+	Mismatch     string   // Mismatch description from Verifier after the FailedStep.
+	Err          error    // Error that failed the step, or caused the timeout.
+	Coverage     int      // Coverage at the end of the run.
+}
+
+// Runner generates tests using a Coverer and executes them online
+// on the system under test through an Adapter.
+type Runner struct {
+	m              Walkable
+	adapter        Adapter
+	coverer        *Coverer
+	lookahead      int           // maxLen of paths searched with BestPath.
+	maxSteps       int           // Maximum number of steps to execute, 0 is unlimited.
+	timeBudget     time.Duration // Maximum duration of a run, 0 is unlimited.
+	coverageTarget int           // Coverage that stops the run, 0 is unlimited.
+}
+
+// NewRunner creates a new runner.
+func NewRunner(m Walkable, adapter Adapter, coverer *Coverer) *Runner {
+	return &Runner{
+		m:         m,
+		adapter:   adapter,
+		coverer:   coverer,
+		lookahead: 6,
+	}
+}
+
+// SetLookahead sets the maximum length of paths searched when
+// choosing the next steps to execute.
+func (r *Runner) SetLookahead(maxLen int) {
+	r.lookahead = maxLen
+}
+
+// SetMaxSteps sets the maximum number of steps to execute.
+func (r *Runner) SetMaxSteps(maxSteps int) {
+	r.maxSteps = maxSteps
+}
+
+// SetTimeBudget sets the maximum duration of a run.
+func (r *Runner) SetTimeBudget(d time.Duration) {
+	r.timeBudget = d
+}
+
+// SetCoverageTarget sets the coverage that stops the run when reached.
+func (r *Runner) SetCoverageTarget(coverage int) {
+	r.coverageTarget = coverage
+}
+
+// Run executes steps starting from an initial state, until coverage
+// cannot be increased anymore, or until a step fails, or the system
+// under test diverges from the model, or a step, time or coverage
+// limit is reached. The coverage limit is either the coverage target
+// or the coverage goal of the Coverer.
+func (r *Runner) Run(ctx context.Context, initial State) *RunResult {
+	if r.timeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeBudget)
+		defer cancel()
+	}
+	observer, _ := r.adapter.(Observer)
+	verifier, _ := r.adapter.(Verifier)
+	result := &RunResult{}
+	state := initial
+	stop := func(reason int, err error) *RunResult {
+		result.Stop = reason
+		result.Err = err
+		result.Coverage = r.coverer.Coverage()
+		return result
+	}
+	for {
+		if (r.coverageTarget > 0 && r.coverer.Coverage() >= r.coverageTarget) || r.coverer.GoalReached() {
+			return stop(RunStopCoverageTarget, nil)
+		}
+		path, stats, err := r.coverer.BestPathContext(ctx, r.m, state, r.lookahead)
+		if err != nil {
+			return stop(RunStopTimeout, err)
+		}
+		if len(path) == 0 {
+			return stop(RunStopNoCoverageIncrease, nil)
+		}
+		for _, step := range path[:stats.FirstStep+1] {
+			if r.maxSteps > 0 && len(result.Trace) >= r.maxSteps {
+				return stop(RunStopMaxSteps, nil)
+			}
+			if err := ctx.Err(); err != nil {
+				return stop(RunStopTimeout, err)
+			}
+			result.Trace = append(result.Trace, step)
+			if err := r.adapter.Execute(ctx, step.action); err != nil {
+				result.FailedStep = step
+				return stop(RunStopFailure, err)
+			}
+			if observer != nil {
+				observation, err := observer.Observe(ctx)
+				if err != nil {
+					result.FailedStep = step
+					return stop(RunStopFailure, err)
+				}
+				result.Observations = append(result.Observations, observation)
+			}
+			if verifier != nil {
+				if mismatch := verifier.Verify(ctx, step.end); mismatch != "" {
+					result.FailedStep = step
+					result.Mismatch = mismatch
+					return stop(RunStopMismatch, nil)
+				}
+			}
+			r.coverer.MarkCovered(step)
+			r.coverer.UpdateCoverage()
+			state = step.end
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// playerAdapter simulates a player as the system under test.
+type playerAdapter struct {
+	playing  bool
+	song     int
+	executed []string
+	failOn   string // Action that fails.
+	bugOn    int    // Song where nextsong does not change the song.
+}
+
+func (pa *playerAdapter) Execute(ctx context.Context, a *Action) error {
+	pa.executed = append(pa.executed, a.String())
+	switch a.String() {
+	case pa.failOn:
+		return fmt.Errorf("%s failed", a)
+	case "play":
+		pa.playing = true
+	case "pause":
+		pa.playing = false
+	case "nextsong":
+		if pa.song != pa.bugOn {
+			pa.song++
+		}
+	case "prevsong":
+		pa.song--
+	}
+	return nil
+}
+
+func (pa *playerAdapter) Observe(ctx context.Context) (string, error) {
+	return (&PlayerState{pa.playing, pa.song}).String(), nil
+}
+
+// verifyingPlayerAdapter verifies the state of the simulated player.
+type verifyingPlayerAdapter struct {
+	playerAdapter
+}
+
+func (vpa *verifyingPlayerAdapter) Verify(ctx context.Context, expected State) string {
+	if observed, _ := vpa.Observe(ctx); observed != expected.String() {
+		return fmt.Sprintf("expected %s, observed %s", expected, observed)
+	}
+	return ""
+}
+
+func TestRunnerVerifier(t *testing.T) {
+	for modelName, model := range playerModels {
+		coverer := NewCoverer()
+		coverer.CoverStateActions()
+		adapter := &verifyingPlayerAdapter{playerAdapter{song: 1, bugOn: 2}}
+		result := NewRunner(model, adapter, coverer).Run(context.Background(), &PlayerState{false, 1})
+		if result.Stop != RunStopMismatch || result.Mismatch == "" {
+			t.Fatalf("model %q: expected mismatch, got stop %d", modelName, result.Stop)
+		}
+		if result.FailedStep.Action().String() != "nextsong" || result.FailedStep.StartState().(*PlayerState).song != 2 {
+			t.Fatalf("model %q: expected mismatch after nextsong from song 2, got %s", modelName, result.FailedStep)
+		}
+		t.Log(result.Mismatch, "after", ActionNames(result.Trace))
+	}
+}
+
+func TestRunner(t *testing.T) {
+	for modelName, model := range playerModels {
+		coverer := NewCoverer()
+		coverer.CoverStateActions()
+		adapter := &playerAdapter{song: 1}
+		result := NewRunner(model, adapter, coverer).Run(context.Background(), &PlayerState{false, 1})
+		if result.Stop != RunStopNoCoverageIncrease || result.Err != nil {
+			t.Fatalf("model %q: unexpected stop %d, error %v", modelName, result.Stop, result.Err)
+		}
+		if result.Coverage != 14 || len(result.Trace) != 14 || len(adapter.executed) != 14 {
+			t.Fatalf("model %q: expected coverage 14 with 14 steps, got %d with %d steps", modelName, result.Coverage, len(result.Trace))
+		}
+		for i, step := range result.Trace {
+			if step.EndState().String() != result.Observations[i] {
+				t.Fatalf("model %q: step %d: expected %s, observed %s", modelName, i, step.EndState(), result.Observations[i])
+			}
+		}
+
+		coverer = NewCoverer()
+		coverer.CoverStateActions()
+		adapter = &playerAdapter{song: 1, failOn: "prevsong"}
+		result = NewRunner(model, adapter, coverer).Run(context.Background(), &PlayerState{false, 1})
+		if result.Stop != RunStopFailure || result.FailedStep == nil || result.FailedStep.Action().String() != "prevsong" {
+			t.Fatalf("model %q: expected prevsong to fail, got stop %d, failed step %s", modelName, result.Stop, result.FailedStep)
+		}
+		if result.Trace[len(result.Trace)-1] != result.FailedStep {
+			t.Fatalf("model %q: expected failed step to end the trace", modelName)
+		}
+
+		for _, limit := range []struct {
+			runner func(*Runner)
+			stop   int
+		}{
+			{func(r *Runner) { r.SetMaxSteps(3) }, RunStopMaxSteps},
+			{func(r *Runner) { r.SetCoverageTarget(5) }, RunStopCoverageTarget},
+			{func(r *Runner) { r.SetTimeBudget(time.Nanosecond) }, RunStopTimeout},
+		} {
+			coverer = NewCoverer()
+			coverer.CoverStateActions()
+			runner := NewRunner(model, &playerAdapter{song: 1}, coverer)
+			limit.runner(runner)
+			result = runner.Run(context.Background(), &PlayerState{false, 1})
+			if result.Stop != limit.stop {
+				t.Fatalf("model %q: expected stop %d, got %d", modelName, limit.stop, result.Stop)
+			}
+		}
+	}
+}
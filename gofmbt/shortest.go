@@ -0,0 +1,165 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"container/heap"
+	"strconv"
+)
+
+// StepCost returns the cost of executing a step.
+type StepCost func(*Step) float64
+
+// ActionCost returns the cost of the action of a step.
+func ActionCost(step *Step) float64 {
+	return step.action.cost
+}
+
+// ShortestPath returns a path with the fewest steps from a state to
+// any state for which target returns true. Paths are searched
+// breadth-first, and states are identified by State.String(). If
+// maxLen > 0, only paths of at most maxLen steps are searched.
+// Returns false if no such path is found. The path is empty if the
+// state itself is a target.
+func (w *Walker) ShortestPath(s State, target func(State) bool, maxLen int) (Path, bool) {
+	if target(s) {
+		return Path{}, true
+	}
+	parent := map[string]*Step{s.String(): nil}
+	frontier := []State{s}
+	for depth := 0; len(frontier) > 0 && (maxLen <= 0 || depth < maxLen); depth++ {
+		nextFrontier := []State{}
+		for _, state := range frontier {
+			for _, step := range w.stepsFrom(state) {
+				endStr := step.end.String()
+				if _, ok := parent[endStr]; ok {
+					continue
+				}
+				parent[endStr] = step
+				if target(step.end) {
+					return pathFromParents(parent, step), true
+				}
+				nextFrontier = append(nextFrontier, step.end)
+			}
+		}
+		frontier = nextFrontier
+	}
+	return nil, false
+}
+
+// CheapestPath returns a path with the smallest total cost from a
+// state to any state for which target returns true, and the total
+// cost of the path. Costs must not be negative. If cost is nil,
+// costs of actions are used. If maxLen > 0, only paths of at most
+// maxLen steps are searched. Then a state is searched separately at
+// every depth, because the cheapest path to a state may be too long
+// to be continued. Returns false if no such path is found.
+func (w *Walker) CheapestPath(s State, target func(State) bool, cost StepCost, maxLen int) (Path, float64, bool) {
+	if cost == nil {
+		cost = ActionCost
+	}
+	nodeKey := func(s State, depth int) string {
+		if maxLen > 0 {
+			return s.String() + "\x00" + strconv.Itoa(depth)
+		}
+		return s.String()
+	}
+	start := &costNode{}
+	nodes := map[string]*costNode{nodeKey(s, 0): start}
+	queue := &costQueue{{state: s, node: start}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(costQueueItem)
+		n := item.node
+		if n.done {
+			continue
+		}
+		n.done = true
+		if target(item.state) {
+			return n.path(), n.cost, true
+		}
+		if maxLen > 0 && n.depth >= maxLen {
+			continue
+		}
+		for _, step := range w.stepsFrom(item.state) {
+			endKey := nodeKey(step.end, n.depth+1)
+			endCost := n.cost + cost(step)
+			endN, ok := nodes[endKey]
+			if ok && (endN.done || endN.cost <= endCost) {
+				continue
+			}
+			if !ok {
+				endN = &costNode{}
+				nodes[endKey] = endN
+			}
+			// Queue items of a more expensive path to the
+			// same node are skipped as the node is done
+			// before them.
+			endN.step, endN.prev, endN.cost, endN.depth = step, n, endCost, n.depth+1
+			heap.Push(queue, costQueueItem{state: step.end, cost: endCost, node: endN})
+		}
+	}
+	return nil, 0, false
+}
+
+// costNode is a state reached in CheapestPath search.
+type costNode struct {
+	step  *Step     // Last step of the cheapest path to the node.
+	prev  *costNode // Node of the start state of the step.
+	cost  float64
+	depth int
+	done  bool // True if the cheapest path to the node is known.
+}
+
+// path returns the cheapest path to the node.
+func (n *costNode) path() Path {
+	path := make(Path, n.depth)
+	for ; n.step != nil; n = n.prev {
+		path[n.depth-1] = n.step
+	}
+	return path
+}
+
+// pathFromParents returns the path that ends with the last step,
+// following parent steps of start states.
+func pathFromParents(parent map[string]*Step, last *Step) Path {
+	path := Path{}
+	for step := last; step != nil; step = parent[step.start.String()] {
+		path = append(path, step)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type costQueueItem struct {
+	state State
+	cost  float64
+	node  *costNode
+}
+
+// costQueue is a priority queue of states, the cheapest first.
+type costQueue []costQueueItem
+
+func (q costQueue) Len() int            { return len(q) }
+func (q costQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q costQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *costQueue) Push(x interface{}) { *q = append(*q, x.(costQueueItem)) }
+func (q *costQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,100 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShortestAndCheapestPath(t *testing.T) {
+	for modelName, model := range playerModels {
+		target := func(s State) bool { return s.String() == "{playing:true,song:3}" }
+		path, ok := NewWalker(model).ShortestPath(&PlayerState{false, 1}, target, 0)
+		if !ok || len(path) != 3 || !target(path[2].EndState()) {
+			t.Fatalf("model %q: expected shortest path of 3 steps, got %v", modelName, path)
+		}
+		if _, ok := NewWalker(model).ShortestPath(&PlayerState{false, 1}, target, 2); ok {
+			t.Fatalf("model %q: expected no path of at most 2 steps", modelName)
+		}
+	}
+
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("a").Do(gotoMyState("X"))),
+			When(ms == "X", OnAction("b").Do(gotoMyState("goal"))),
+			When(ms == "start", OnAction("c").Do(gotoMyState("Y"))),
+			When(ms == "Y", OnAction("d").Do(gotoMyState("Z"))),
+			When(ms == "Z", OnAction("e").Do(gotoMyState("goal"))),
+		)
+	})
+	w := NewWalker(model)
+	isGoal := func(s State) bool { return s.String() == "goal" }
+	cost := func(step *Step) float64 {
+		if a := step.Action().String(); a == "a" || a == "b" {
+			return 5
+		}
+		return 1
+	}
+	if path, ok := w.ShortestPath(MyState("start"), isGoal, 0); !ok || strings.Join(ActionNames(path), "") != "ab" {
+		t.Fatalf("expected shortest path ab, got %v", path)
+	}
+	if path, total, ok := w.CheapestPath(MyState("start"), isGoal, cost, 0); !ok || strings.Join(ActionNames(path), "") != "cde" || total != 3 {
+		t.Fatalf("expected cheapest path cde with cost 3, got %v with cost %v", path, total)
+	}
+	if path, total, ok := w.CheapestPath(MyState("start"), isGoal, cost, 2); !ok || strings.Join(ActionNames(path), "") != "ab" || total != 10 {
+		t.Fatalf("expected cheapest path ab with cost 10 within 2 steps, got %v with cost %v", path, total)
+	}
+	if path, _, ok := w.CheapestPath(MyState("goal"), isGoal, cost, 0); !ok || len(path) != 0 {
+		t.Fatalf("expected empty path from goal to goal, got %v", path)
+	}
+
+	// The cheapest path to M is too long to reach G within two steps.
+	model = NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "S", OnAction("exp").WithCost(10).Do(gotoMyState("M"))),
+			When(ms == "S", OnAction("c1").Do(gotoMyState("X"))),
+			When(ms == "X", OnAction("c2").Do(gotoMyState("M"))),
+			When(ms == "M", OnAction("g").Do(gotoMyState("G"))),
+		)
+	})
+	isG := func(s State) bool { return s.String() == "G" }
+	if path, total, ok := NewWalker(model).CheapestPath(MyState("S"), isG, nil, 2); !ok || strings.Join(ActionNames(path), ",") != "exp,g" || total != 11 {
+		t.Fatalf("expected cheapest path exp,g with cost 11 within 2 steps, got %v with cost %v", path, total)
+	}
+	if path, total, ok := NewWalker(model).CheapestPath(MyState("S"), isG, nil, 0); !ok || strings.Join(ActionNames(path), ",") != "c1,c2,g" || total != 3 {
+		t.Fatalf("expected cheapest path c1,c2,g with cost 3, got %v with cost %v", path, total)
+	}
+}
+
+func TestCheapestPathActionCost(t *testing.T) {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("upload").WithCost(5).Do(gotoMyState("goal"))),
+			When(ms == "start", OnAction("a").Do(gotoMyState("X"))),
+			When(ms == "X", OnAction("b").Do(gotoMyState("goal"))),
+		)
+	})
+	isGoal := func(s State) bool { return s.String() == "goal" }
+	if path, total, ok := NewWalker(model).CheapestPath(MyState("start"), isGoal, nil, 0); !ok || strings.Join(ActionNames(path), "") != "ab" || total != 2 {
+		t.Fatalf("expected cheapest path ab with cost 2, got %v with cost %v", path, total)
+	}
+}
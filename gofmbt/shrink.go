@@ -0,0 +1,107 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+// Shrink searches for a shorter path that still fails. The path
+// starts from the initial state and fails returns true if executing
+// a path still fails. Shrinking eliminates loops between repeated
+// states and removes ranges of steps in delta-debugging style. Only
+// paths that are valid in the model are tried: end states of steps
+// are re-derived with StepsFrom after every removal. Returns the
+// shortest failing path found.
+func Shrink(m Walkable, initial State, path Path, fails func(Path) bool) Path {
+	for shrunk := true; shrunk; {
+		shrunk = false
+		if p := shrinkLoops(path, fails); p != nil {
+			path, shrunk = p, true
+		}
+		if p := shrinkRanges(m, initial, path, fails); p != nil {
+			path, shrunk = p, true
+		}
+	}
+	return path
+}
+
+// shrinkLoops removes the longest loop, that is a subpath that starts
+// and ends in the same state, that can be removed without making the
+// path pass. Returns nil if no loop can be removed.
+func shrinkLoops(path Path, fails func(Path) bool) Path {
+	states := StateStrings(path)
+	for loopLen := len(path); loopLen > 0; loopLen-- {
+		for first := 0; first+loopLen <= len(path); first++ {
+			if states[first] != states[first+loopLen] {
+				continue
+			}
+			candidate := append(append(Path{}, path[:first]...), path[first+loopLen:]...)
+			if fails(candidate) {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+// shrinkRanges removes ranges of steps, starting from ranges of half
+// of the path down to single steps. Returns nil if nothing can be
+// removed.
+func shrinkRanges(m Walkable, initial State, path Path, fails func(Path) bool) Path {
+	var shrunk Path
+	for rangeLen := len(path) / 2; rangeLen > 0; rangeLen /= 2 {
+		for first := 0; first+rangeLen <= len(path); {
+			steps := append(append(Path{}, path[:first]...), path[first+rangeLen:]...)
+			if candidate := rederivePath(m, initial, steps); candidate != nil && fails(candidate) {
+				path, shrunk = candidate, candidate
+				continue
+			}
+			first += rangeLen
+		}
+	}
+	return shrunk
+}
+
+// rederivePath returns a path that starts from the initial state and
+// executes the same actions as the steps, or nil if some of the
+// actions is not possible in the model.
+func rederivePath(m Walkable, initial State, steps Path) Path {
+	path := make(Path, 0, len(steps))
+	state := initial
+	for _, step := range steps {
+		next := matchingStep(m.StepsFrom(state), step.action.String(), step.end.String())
+		if next == nil {
+			return nil
+		}
+		path = append(path, next)
+		state = next.end
+	}
+	return path
+}
+
+// matchingStep returns a step with the action, preferably ending to
+// the end state. Returns nil if there is no step with the action.
+func matchingStep(steps []*Step, action, end string) *Step {
+	var match *Step
+	for _, step := range steps {
+		if step.action.String() != action {
+			continue
+		}
+		if step.end.String() == end {
+			return step
+		}
+		if match == nil {
+			match = step
+		}
+	}
+	return match
+}
@@ -0,0 +1,54 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"testing"
+)
+
+func TestShrink(t *testing.T) {
+	for modelName, model := range playerModels {
+		initial := &PlayerState{false, 1}
+		// The system under test fails when pausing the last song.
+		fails := func(path Path) bool {
+			for _, step := range path {
+				if step.StartState().String() == "{playing:true,song:3}" && step.Action().String() == "pause" {
+					return true
+				}
+			}
+			return false
+		}
+		steps := Path{}
+		for _, action := range []string{"play", "nextsong", "pause", "play", "prevsong", "nextsong", "nextsong", "pause", "prevsong", "play", "nextsong", "pause", "play"} {
+			steps = append(steps, NewStep(nil, NewAction(action), &PlayerState{}))
+		}
+		path := rederivePath(model, initial, steps)
+		if path == nil || !fails(path) {
+			t.Fatalf("model %q: invalid original path %v", modelName, path)
+		}
+		calls := 0
+		shrunk := Shrink(model, initial, path, func(p Path) bool {
+			calls++
+			return fails(p)
+		})
+		t.Log("shrunk", ActionNames(path), "to", ActionNames(shrunk), "in", calls, "calls")
+		if len(shrunk) != 4 || !fails(shrunk) {
+			t.Fatalf("model %q: expected a failing path of 4 steps, got %v", modelName, ActionNames(shrunk))
+		}
+		if rederivePath(model, initial, shrunk) == nil {
+			t.Fatalf("model %q: shrunk path %v is not valid", modelName, shrunk)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"fmt"
+)
+
+// SubModel embeds a model inside states of parent models. A parent
+// model enters the sub-model with a transition whose StateChange is
+// Enter(). While in the sub-model, only transitions of the sub-model
+// are possible. The sub-model returns to the parent state with a
+// transition whose StateChange is ExitSubModel(). The same SubModel
+// can be entered from many states and from many parent models.
+type SubModel struct {
+	name   string
+	model  *Model
+	entry  State                          // First state in the sub-model.
+	onExit func(outer, inner State) State // Returns the parent state after exit.
+}
+
+// SubModelState is a state inside a sub-model.
+type SubModelState struct {
+	sub   *SubModel
+	Outer State // Parent state where the sub-model was entered.
+	Inner State // State of the sub-model.
+}
+
+// exitState is returned by StateChanges that exit a sub-model.
+type exitState struct{}
+
+func (exitState) String() string {
+	return "exit"
+}
+
+// NewSubModel creates a new sub-model that starts from the entry
+// state when entered.
+func NewSubModel(name string, m *Model, entry State) *SubModel {
+	return &SubModel{
+		name:  name,
+		model: m,
+		entry: entry,
+		onExit: func(outer, _ State) State {
+			return outer
+		},
+	}
+}
+
+// Name returns the name of the sub-model.
+func (sm *SubModel) Name() string {
+	return sm.name
+}
+
+// SetOnExit sets a function that returns the parent state after
+// exiting the sub-model, given the parent state where the sub-model
+// was entered and the last state in the sub-model. By default the
+// parent state does not change.
+func (sm *SubModel) SetOnExit(onExit func(outer, inner State) State) {
+	sm.onExit = onExit
+}
+
+// Enter returns a StateChange that enters the sub-model.
+func (sm *SubModel) Enter() StateChange {
+	return func(outer State) State {
+		return &SubModelState{sub: sm, Outer: outer, Inner: sm.entry}
+	}
+}
+
+// ExitSubModel returns a StateChange that exits the current
+// sub-model. It is used in transitions of sub-models.
+func ExitSubModel() StateChange {
+	return func(_ State) State {
+		return exitState{}
+	}
+}
+
+// String returns the parent state, the name of the sub-model and
+// the state in the sub-model.
+func (s *SubModelState) String() string {
+	return fmt.Sprintf("%s/%s:%s", s.Outer, s.sub.name, s.Inner)
+}
+
+// SubModel returns the sub-model of the state.
+func (s *SubModelState) SubModel() *SubModel {
+	return s.sub
+}
+
+// transitions returns transitions of the sub-model in a state,
+// mapped to the parent model.
+func (sm *SubModel) transitions(s *SubModelState) []*Transition {
+	ts := []*Transition{}
+	for _, t := range sm.model.TransitionsFrom(s.Inner) {
+		ts = append(ts, NewTransition(t.action, func(current State) State {
+			cs := current.(*SubModelState)
+			end := t.stateChange(cs.Inner)
+			switch end.(type) {
+			case nil:
+				return nil
+			case exitState:
+				return sm.onExit(cs.Outer, cs.Inner)
+			}
+			return &SubModelState{sub: sm, Outer: cs.Outer, Inner: end}
+		}))
+	}
+	return ts
+}
+
+// innerState returns the state inside a sub-model, possibly nested
+// in other sub-models, if s is a state in the sub-model.
+func (sm *SubModel) innerState(s State) (State, bool) {
+	for {
+		ss, ok := s.(*SubModelState)
+		if !ok {
+			return nil, false
+		}
+		if ss.sub == sm {
+			return ss.Inner, true
+		}
+		s = ss.Inner
+	}
+}
+
+// CoverSubModel starts counting covered state-action pairs inside a
+// sub-model. Pairs are counted in states of the sub-model, no matter
+// where the sub-model was entered from. The name of the coverage
+// function is "submodel:" followed by the name of the sub-model.
+func (c *Coverer) CoverSubModel(sm *SubModel) {
+	sep := "\x00"
+	c.addCovFunc("submodel:"+sm.name, 0, 1, func(path Path) []string {
+		stateActions := []string{}
+		for _, step := range path {
+			if inner, ok := sm.innerState(step.start); ok {
+				stateActions = append(stateActions, sm.name+sep+inner.String()+sep+step.action.String())
+			}
+		}
+		return stateActions
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"testing"
+)
+
+func TestSubModel(t *testing.T) {
+	settingsModel := NewModel()
+	settingsModel.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "wifi-off", OnAction("toggle").Do(gotoMyState("wifi-on"))),
+			When(ms == "wifi-on", OnAction("toggle").Do(gotoMyState("wifi-off"))),
+			OnAction("back").Do(ExitSubModel()),
+		)
+	})
+	settings := NewSubModel("settings", settingsModel, MyState("wifi-off"))
+	app := NewModel()
+	app.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "home", OnAction("open-player").Do(gotoMyState("player"))),
+			When(ms == "player", OnAction("close-player").Do(gotoMyState("home"))),
+			OnAction("open-settings").Do(settings.Enter()),
+		)
+	})
+	ss := app.Explore(MyState("home"), ExploreLimits{})
+	if len(ss.States) != 6 {
+		t.Fatalf("expected 6 states, got %v", ss.Order)
+	}
+	if err := CheckDeadEnds(app, MyState("home"), ExploreLimits{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	path, ok := NewWalker(app).ShortestPath(MyState("home"), func(s State) bool { return s.String() == "player/settings:wifi-on" }, 0)
+	if !ok || len(path) != 3 {
+		t.Fatalf("expected path of 3 steps to player/settings:wifi-on, got %v", path)
+	}
+
+	coverer := NewCoverer()
+	coverer.CoverSubModel(settings)
+	coverer.SetUniverse(ss)
+	coverer.MarkCovered(path...)
+	steps := app.StepsFrom(path[2].EndState())
+	coverer.MarkCovered(matchingStep(steps, "back", ""))
+	coverer.UpdateCoverage()
+	report := coverer.CoverageReport()
+	if len(report) != 1 || report[0].Name != "submodel:settings" || report[0].Covered != 2 || report[0].Total != 4 {
+		t.Fatalf("expected 2/4 state-actions covered in settings, got %+v", report[0])
+	}
+	if end := path[len(path)-1].EndState(); coverer.coveredPath[3].EndState().String() != "player" {
+		t.Fatalf("expected back to exit from %s to player, got %s", end, coverer.coveredPath[3].EndState())
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"errors"
+)
+
+// GenerateSuite generates independent test cases that start from the
+// initial state of a model. Test cases are generated with BestPath
+// using lookahead as maxLen, until coverage cannot be increased or
+// the coverage goal of the Coverer is reached. A test case ends when
+// coverage cannot be increased without a reset, or when it has
+// maxCaseLen steps (0 is unlimited). Generation stops when a test
+// case does not increase coverage, for instance because uncovered
+// elements are further than maxCaseLen steps from the initial state.
+func GenerateSuite(m *Model, c *Coverer, lookahead, maxCaseLen int) ([]Path, error) {
+	if m.initial == nil {
+		return nil, errors.New("model has no initial state")
+	}
+	cases := []Path{}
+	for !c.GoalReached() {
+		c.StartNewPath()
+		coverageBefore := c.Coverage()
+		tc := Path{}
+		state := m.initial
+		for !c.GoalReached() && (maxCaseLen <= 0 || len(tc) < maxCaseLen) {
+			path, stats := c.BestPath(m, state, lookahead)
+			if len(path) == 0 {
+				break
+			}
+			for _, step := range path[:stats.FirstStep+1] {
+				if maxCaseLen > 0 && len(tc) >= maxCaseLen {
+					break
+				}
+				tc = append(tc, step)
+				c.MarkCovered(step)
+				c.UpdateCoverage()
+				state = step.end
+			}
+		}
+		if len(tc) == 0 || c.Coverage() == coverageBefore {
+			break
+		}
+		cases = append(cases, tc)
+	}
+	return cases, nil
+}
+
+// JoinSuite joins test cases into a single path, where test cases
+// are separated by reset steps. This allows executing a suite in a
+// single session. Empty test cases are skipped.
+func (m *Model) JoinSuite(cases []Path) (Path, error) {
+	if m.ResetStep(m.initial) == nil {
+		return nil, errors.New("model has no initial state or reset action")
+	}
+	joined := Path{}
+	for _, tc := range cases {
+		if len(tc) == 0 {
+			continue
+		}
+		if len(joined) > 0 {
+			joined = append(joined, m.ResetStep(joined[len(joined)-1].end))
+		}
+		joined = append(joined, tc...)
+	}
+	return joined, nil
+}
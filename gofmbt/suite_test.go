@@ -0,0 +1,98 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"testing"
+)
+
+func TestGenerateSuite(t *testing.T) {
+	for modelName, newModel := range map[string]func() *Model{
+		"raw":  newPlayerModelWithRawTransitions,
+		"when": newPlayerModelWithWhenOnAction,
+	} {
+		model := newModel()
+		if _, err := GenerateSuite(model, NewCoverer(), 6, 0); err == nil {
+			t.Fatalf("model %q: expected error without initial state", modelName)
+		}
+		model.SetInitialState(&PlayerState{false, 1})
+		model.SetResetAction(NewAction("reset"))
+		coverer := NewCoverer()
+		coverer.CoverStateActions()
+		coverer.CoverActionCombinations(2)
+		cases, err := GenerateSuite(model, coverer, 6, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cases) < 2 {
+			t.Fatalf("model %q: expected many test cases, got %d", modelName, len(cases))
+		}
+		for i, tc := range cases {
+			t.Log("test case", i, ActionNames(tc))
+			if len(tc) > 5 || tc[0].StartState().String() != model.InitialState().String() {
+				t.Fatalf("model %q: test case %d does not start from the initial state or is too long: %v", modelName, i, tc)
+			}
+		}
+
+		// Every test case is covered from the initial state, and the
+		// joined suite has a reset step between test cases.
+		joined, err := model.JoinSuite(cases)
+		if err != nil {
+			t.Fatal(err)
+		}
+		suiteCoverer := NewCoverer()
+		suiteCoverer.CoverStateActions()
+		for _, tc := range cases {
+			suiteCoverer.StartNewPath()
+			suiteCoverer.MarkCovered(tc...)
+		}
+		suiteCoverer.UpdateCoverage()
+		if suiteCoverer.Coverage() != 14 {
+			t.Fatalf("model %q: expected suite to cover 14 state-actions, got %d", modelName, suiteCoverer.Coverage())
+		}
+		if len(joined) != len(cases)-1+len(suiteCoverer.coveredPath) || !validTour(joined) {
+			t.Fatalf("model %q: invalid joined suite %v", modelName, joined)
+		}
+	}
+}
+
+func TestGenerateSuiteMaxCaseLen(t *testing.T) {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		next := map[MyState]string{"0": "1", "1": "2", "2": "3", "3": "4", "4": "5", "5": "6"}[ms]
+		return When(next != "", OnAction("next").Do(gotoMyState(next)))
+	})
+	model.SetInitialState(MyState("0"))
+	model.SetResetAction(NewAction("reset"))
+	coverer := NewCoverer()
+	coverer.CoverStates()
+	// States beyond 3 cannot be reached within maxCaseLen steps.
+	cases, err := GenerateSuite(model, coverer, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 1 || len(cases[0]) != 3 || coverer.Coverage() != 4 {
+		t.Fatalf("expected one test case covering 4 states, got %v covering %d", cases, coverer.Coverage())
+	}
+
+	joined, err := model.JoinSuite([]Path{{}, cases[0], {}, cases[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joined) != 7 || joined[3].Action().String() != "reset" || !validTour(joined) {
+		t.Fatalf("expected two test cases joined with a reset, got %v", joined)
+	}
+}
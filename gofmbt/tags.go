@@ -0,0 +1,90 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"fmt"
+)
+
+// tagPrefix separates covered tags from covered action names.
+const tagPrefix = "tag:"
+
+// TagCombinations returns combinations of tags of combLen consecutive
+// steps in a path. Every combination contains one tag of every step.
+func TagCombinations(path Path, combLen int) []string {
+	tagSep := "\x00"
+	combs := []string{}
+	if combLen < 1 {
+		return combs
+	}
+	for first := 0; first <= len(path)-combLen; first++ {
+		stepCombs := path[first].action.tags
+		for _, step := range path[first+1 : first+combLen] {
+			nextCombs := []string{}
+			for _, comb := range stepCombs {
+				for _, tag := range step.action.tags {
+					nextCombs = append(nextCombs, comb+tagSep+tag)
+				}
+			}
+			stepCombs = nextCombs
+		}
+		combs = append(combs, stepCombs...)
+	}
+	return combs
+}
+
+// CoverTags starts counting covered action tags.
+func (c *Coverer) CoverTags() {
+	c.addCovFunc("tags", 0, 0, func(path Path) []string {
+		covered := []string{}
+		for _, tag := range TagCombinations(path, 1) {
+			covered = append(covered, tagPrefix+tag)
+		}
+		return covered
+	})
+}
+
+// CoverTagCombinations starts counting covered combinations of tags
+// of up to combLenMax consecutive steps.
+func (c *Coverer) CoverTagCombinations(combLenMax int) {
+	c.addCovFunc(fmt.Sprintf("tag-combinations(%d)", combLenMax), combLenMax, 0, func(path Path) []string {
+		covered := []string{}
+		for combLen := 1; combLen <= combLenMax; combLen++ {
+			for _, comb := range TagCombinations(path, combLen) {
+				covered = append(covered, tagPrefix+comb)
+			}
+		}
+		return covered
+	})
+}
+
+// TagReport returns steps that have covered each tag. Only steps
+// whose coverage has been updated are reported, and every step is
+// reported once per tag even if it has been covered many times.
+func (c *Coverer) TagReport() map[string][]*Step {
+	report := map[string][]*Step{}
+	reported := map[string]bool{}
+	for _, step := range c.coveredPath[:c.updatedLen] {
+		for _, tag := range step.action.tags {
+			key := tag + "\x00" + step.String()
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			report[tag] = append(report[tag], step)
+		}
+	}
+	return report
+}
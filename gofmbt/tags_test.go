@@ -0,0 +1,68 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"testing"
+)
+
+func newTaggedModel() *Model {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "out", OnAction("login").WithTags("ui", "network").Do(gotoMyState("in"))),
+			When(ms == "in", OnAction("upload").WithTags("network", "requirement:REQ-12").Do(gotoMyState("in"))),
+			When(ms == "in", OnAction("logout").WithTags("ui", "ui").Do(gotoMyState("out"))),
+		)
+	})
+	return model
+}
+
+func TestTagCoverage(t *testing.T) {
+	model := newTaggedModel()
+	coverer := NewCoverer()
+	coverer.CoverTags()
+	coverer.CoverTagCombinations(2)
+	coverer.SetUniverse(model.Explore(MyState("out"), ExploreLimits{}))
+	login := model.StepsFrom(MyState("out"))[0]
+	upload := model.StepsFrom(MyState("in"))[0]
+	coverer.MarkCovered(login, upload, upload)
+	coverer.UpdateCoverage()
+	expected := map[string][2]int{
+		"tags":                {3, 3},
+		"tag-combinations(2)": {3 + 6, 3 + 9},
+	}
+	for _, fc := range coverer.CoverageReport() {
+		t.Logf("%s: %d/%d, uncovered: %q", fc.Name, fc.Covered, fc.Total, fc.Uncovered)
+		if exp := expected[fc.Name]; fc.Covered != exp[0] || fc.Total != exp[1] {
+			t.Fatalf("%s: expected %d/%d covered, got %d/%d", fc.Name, exp[0], exp[1], fc.Covered, fc.Total)
+		}
+	}
+	report := coverer.TagReport()
+	expectedSteps := map[string]int{"ui": 1, "network": 2, "requirement:REQ-12": 1}
+	if len(report) != len(expectedSteps) {
+		t.Fatalf("expected tags %v, got %v", expectedSteps, report)
+	}
+	for tag, steps := range expectedSteps {
+		if len(report[tag]) != steps {
+			t.Fatalf("tag %q: expected %d steps, got %v", tag, steps, report[tag])
+		}
+	}
+	path, _ := coverer.BestPath(model, upload.EndState(), 2)
+	if len(path) != 2 || path[0].Action().String() != "logout" {
+		t.Fatalf("expected path starting with logout, got %v", path)
+	}
+}
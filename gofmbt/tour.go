@@ -0,0 +1,313 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+// TransitionTour explores a model from an initial state and returns
+// walks that together cover every state-action pair in the explored
+// state space. If every state can be reached from every other state,
+// the result is a single walk that is a solution of the directed
+// Chinese postman problem: a shortest closed walk from the initial
+// state that takes every state-action pair at least once. Otherwise
+// walks are generated greedily, always continuing to the nearest
+// state-action pair that has not been taken yet, and starting a new
+// walk from the initial state (that is, after a reset) when no
+// untaken pair is reachable anymore. Returns a *NondeterminismError
+// if the model is not deterministic, because then a walk cannot
+// choose the end state of a step.
+func TransitionTour(m Walkable, initial State, limits ExploreLimits) ([]Path, error) {
+	ss := Explore(m, initial, limits)
+	if nds := ss.Nondeterminism(); len(nds) > 0 {
+		return nil, &NondeterminismError{Nondeterminism: nds}
+	}
+	g := newTourGraph(ss)
+	if len(g.edges) == 0 {
+		return nil, nil
+	}
+	if g.stronglyConnected() {
+		return []Path{g.postmanTour()}, nil
+	}
+	return g.greedyTours(), nil
+}
+
+// tourGraph is a graph of states with one edge for every state-action
+// pair.
+type tourGraph struct {
+	ss    *StateSpace
+	index map[string]int // Index of a state in ss.Order.
+	edges []*Step
+	out   [][]int   // Indices of edges from a state.
+	dist  [][]int   // Shortest distances between states, -1 if unreachable.
+	next  [][]*Step // First step of a shortest path between states.
+}
+
+func newTourGraph(ss *StateSpace) *tourGraph {
+	g := &tourGraph{
+		ss:    ss,
+		index: map[string]int{},
+		out:   make([][]int, len(ss.Order)),
+	}
+	for i, s := range ss.Order {
+		g.index[s] = i
+	}
+	for _, s := range ss.Order {
+		seen := map[string]bool{}
+		for _, step := range ss.out[s] {
+			if seen[step.action.String()] {
+				continue
+			}
+			seen[step.action.String()] = true
+			g.out[g.index[s]] = append(g.out[g.index[s]], len(g.edges))
+			g.edges = append(g.edges, step)
+		}
+	}
+	g.computeDistances()
+	return g
+}
+
+// computeDistances computes shortest distances between all states
+// with breadth-first search from every state.
+func (g *tourGraph) computeDistances() {
+	n := len(g.ss.Order)
+	g.dist = make([][]int, n)
+	g.next = make([][]*Step, n)
+	for from := 0; from < n; from++ {
+		g.dist[from] = make([]int, n)
+		g.next[from] = make([]*Step, n)
+		for to := range g.dist[from] {
+			g.dist[from][to] = -1
+		}
+		g.dist[from][from] = 0
+		queue := []int{from}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for _, e := range g.out[v] {
+				u := g.index[g.edges[e].end.String()]
+				if g.dist[from][u] != -1 {
+					continue
+				}
+				g.dist[from][u] = g.dist[from][v] + 1
+				if v == from {
+					g.next[from][u] = g.edges[e]
+				} else {
+					g.next[from][u] = g.next[from][v]
+				}
+				queue = append(queue, u)
+			}
+		}
+	}
+}
+
+func (g *tourGraph) stronglyConnected() bool {
+	for from := range g.dist {
+		for _, d := range g.dist[from] {
+			if d == -1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// shortestPath returns a shortest path between states.
+func (g *tourGraph) shortestPath(from, to int) Path {
+	path := Path{}
+	for from != to {
+		step := g.next[from][to]
+		path = append(path, step)
+		from = g.index[step.end.String()]
+	}
+	return path
+}
+
+// postmanTour returns a shortest closed walk from the initial state
+// that takes every edge. The graph must be strongly connected.
+func (g *tourGraph) postmanTour() Path {
+	n := len(g.ss.Order)
+	// Balance the graph: states with more incoming than outgoing
+	// edges need extra outgoing paths to states with more outgoing
+	// than incoming edges. Extra paths are chosen with minimum cost
+	// flow so that their total length is minimal.
+	balance := make([]int, n)
+	for _, step := range g.edges {
+		balance[g.index[step.start.String()]]--
+		balance[g.index[step.end.String()]]++
+	}
+	multiplicity := make([]int, len(g.edges))
+	for i := range multiplicity {
+		multiplicity[i] = 1
+	}
+	for _, pair := range minCostPairing(balance, g.dist) {
+		for _, step := range g.shortestPath(pair[0], pair[1]) {
+			for _, e := range g.out[g.index[step.start.String()]] {
+				if g.edges[e] == step {
+					multiplicity[e]++
+				}
+			}
+		}
+	}
+	// Find an Eulerian circuit with Hierholzer's algorithm.
+	remaining := make([][]int, n)
+	for v, edges := range g.out {
+		for _, e := range edges {
+			for i := 0; i < multiplicity[e]; i++ {
+				remaining[v] = append(remaining[v], e)
+			}
+		}
+	}
+	circuit := []int{}
+	stack := []int{}
+	vertexStack := []int{0}
+	for len(vertexStack) > 0 {
+		v := vertexStack[len(vertexStack)-1]
+		if len(remaining[v]) > 0 {
+			e := remaining[v][0]
+			remaining[v] = remaining[v][1:]
+			stack = append(stack, e)
+			vertexStack = append(vertexStack, g.index[g.edges[e].end.String()])
+			continue
+		}
+		vertexStack = vertexStack[:len(vertexStack)-1]
+		if len(stack) > 0 {
+			circuit = append(circuit, stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+		}
+	}
+	tour := make(Path, len(circuit))
+	for i, e := range circuit {
+		tour[len(circuit)-1-i] = g.edges[e]
+	}
+	return tour
+}
+
+// minCostPairing returns pairs of states (from, to) where from has
+// positive and to has negative balance, so that every unit of
+// balance is paired and the sum of distances between pairs is
+// minimal. It implements successive shortest paths on the
+// transportation problem.
+func minCostPairing(balance []int, dist [][]int) [][2]int {
+	sources, sinks := []int{}, []int{}
+	for v, b := range balance {
+		for i := 0; i < b; i++ {
+			sources = append(sources, v)
+		}
+		for i := 0; i < -b; i++ {
+			sinks = append(sinks, v)
+		}
+	}
+	// Residual graph: node 0 is the super source, 1...len(sources)
+	// are sources, then sinks, and the last node is the super sink.
+	n := len(sources) + len(sinks) + 2
+	sink := n - 1
+	capacity := make([][]int, n)
+	cost := make([][]int, n)
+	for i := range capacity {
+		capacity[i] = make([]int, n)
+		cost[i] = make([]int, n)
+	}
+	for i, from := range sources {
+		capacity[0][1+i] = 1
+		for j, to := range sinks {
+			u, v := 1+i, 1+len(sources)+j
+			capacity[u][v] = 1
+			cost[u][v] = dist[from][to]
+			cost[v][u] = -dist[from][to]
+		}
+	}
+	for j := range sinks {
+		capacity[1+len(sources)+j][sink] = 1
+	}
+	const infinity = int(^uint(0) >> 2)
+	for range sources {
+		// Find the cheapest augmenting path with Bellman-Ford.
+		distance := make([]int, n)
+		prev := make([]int, n)
+		for i := range distance {
+			distance[i] = infinity
+			prev[i] = -1
+		}
+		distance[0] = 0
+		for changed := true; changed; {
+			changed = false
+			for u := 0; u < n; u++ {
+				for v := 0; v < n; v++ {
+					if capacity[u][v] > 0 && distance[u] != infinity && distance[u]+cost[u][v] < distance[v] {
+						distance[v] = distance[u] + cost[u][v]
+						prev[v] = u
+						changed = true
+					}
+				}
+			}
+		}
+		for v := sink; v != 0; v = prev[v] {
+			capacity[prev[v]][v]--
+			capacity[v][prev[v]]++
+		}
+	}
+	pairs := [][2]int{}
+	for i, from := range sources {
+		for j, to := range sinks {
+			if capacity[1+len(sources)+j][1+i] > 0 {
+				pairs = append(pairs, [2]int{from, to})
+			}
+		}
+	}
+	return pairs
+}
+
+// greedyTours returns walks from the initial state that cover all
+// edges, always continuing to the nearest untaken edge.
+func (g *tourGraph) greedyTours() []Path {
+	taken := make([]bool, len(g.edges))
+	untaken := len(g.edges)
+	tours := []Path{}
+	for untaken > 0 {
+		tour := Path{}
+		v := 0
+		for {
+			// Find the nearest state with an untaken edge.
+			nearest, nearestEdge := -1, -1
+			for u := range g.out {
+				if g.dist[v][u] == -1 || (nearest != -1 && g.dist[v][u] >= g.dist[v][nearest]) {
+					continue
+				}
+				for _, e := range g.out[u] {
+					if !taken[e] {
+						nearest, nearestEdge = u, e
+						break
+					}
+				}
+			}
+			if nearest == -1 {
+				break
+			}
+			for _, step := range append(g.shortestPath(v, nearest), g.edges[nearestEdge]) {
+				for _, e := range g.out[g.index[step.start.String()]] {
+					if g.edges[e] == step && !taken[e] {
+						taken[e] = true
+						untaken--
+					}
+				}
+				tour = append(tour, step)
+			}
+			v = g.index[g.edges[nearestEdge].end.String()]
+		}
+		if len(tour) == 0 {
+			break
+		}
+		tours = append(tours, tour)
+	}
+	return tours
+}
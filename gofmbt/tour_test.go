@@ -0,0 +1,108 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"strings"
+	"testing"
+)
+
+// validTour returns true if consecutive steps in a path connect.
+func validTour(path Path) bool {
+	for i := 1; i < len(path); i++ {
+		if path[i].StartState().String() != path[i-1].EndState().String() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTransitionTour(t *testing.T) {
+	for modelName, model := range playerModels {
+		initial := &PlayerState{false, 1}
+		tours, err := TransitionTour(model, initial, ExploreLimits{})
+		if err != nil {
+			t.Fatalf("model %q: %s", modelName, err)
+		}
+		if len(tours) != 1 || len(tours[0]) != 14 {
+			t.Fatalf("model %q: expected one tour of 14 steps, got %v", modelName, tours)
+		}
+		tour := tours[0]
+		if !validTour(tour) || tour[0].StartState().String() != initial.String() || tour[13].EndState().String() != initial.String() {
+			t.Fatalf("model %q: expected a closed walk from the initial state, got %v", modelName, tour)
+		}
+		coverer := NewCoverer()
+		coverer.CoverStateActions()
+		coverer.MarkCovered(tour...)
+		coverer.UpdateCoverage()
+		if coverer.Coverage() != 14 {
+			t.Fatalf("model %q: expected tour to cover 14 state-actions, got %d", modelName, coverer.Coverage())
+		}
+	}
+
+	// Unbalanced: start has more outgoing than incoming edges.
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("a").Do(gotoMyState("A"))),
+			When(ms == "start", OnAction("b").Do(gotoMyState("B"))),
+			When(ms == "A", OnAction("d").Do(gotoMyState("B"))),
+			When(ms == "B", OnAction("c").Do(gotoMyState("start"))),
+		)
+	})
+	tours, err := TransitionTour(model, MyState("start"), ExploreLimits{})
+	if err != nil || len(tours) != 1 || len(tours[0]) != 5 || !validTour(tours[0]) {
+		t.Fatalf("expected one tour of 5 steps, got %v", tours)
+	}
+
+	// Not strongly connected: tours need resets.
+	model = NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("a").Do(gotoMyState("A"))),
+			When(ms == "start", OnAction("b").Do(gotoMyState("B"))),
+			When(ms == "B", OnAction("c").Do(gotoMyState("C"))),
+		)
+	})
+	traces := []string{}
+	tours, err = TransitionTour(model, MyState("start"), ExploreLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tour := range tours {
+		traces = append(traces, strings.Join(ActionNames(tour), ""))
+	}
+	if strings.Join(traces, " ") != "a bc" {
+		t.Fatalf("expected tours a and bc, got %v", traces)
+	}
+
+	// Nondeterministic: action a may lead to A or B.
+	model = NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("a").Do(gotoMyState("A"))),
+			When(ms == "start", OnAction("a").Do(gotoMyState("B"))),
+			When(ms == "B", OnAction("b").Do(gotoMyState("start"))),
+		)
+	})
+	if _, err := TransitionTour(model, MyState("start"), ExploreLimits{}); err == nil {
+		t.Fatal("expected error on nondeterministic model")
+	} else if _, ok := err.(*NondeterminismError); !ok {
+		t.Fatalf("expected *NondeterminismError, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeadEnd is a reachable state without outgoing steps.
+type DeadEnd struct {
+	State State // State where no steps are possible.
+	Path  Path  // Shortest path from the initial state to the State.
+}
+
+// DeadEndError is returned when a model has unexpected dead ends.
+type DeadEndError struct {
+	DeadEnds []*DeadEnd
+}
+
+// Error returns dead-end states and paths that lead to them.
+func (e *DeadEndError) Error() string {
+	lines := []string{fmt.Sprintf("%d dead-end state(s) found", len(e.DeadEnds))}
+	for _, de := range e.DeadEnds {
+		lines = append(lines, fmt.Sprintf("  %s after actions [%s]", de.State, strings.Join(ActionNames(de.Path), ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DeadEnds returns explored states that have no outgoing steps. If
+// isFinal is not nil, states for which isFinal returns true are
+// legitimate final states and they are not reported. States that
+// were not explored due to exploration limits are never dead ends.
+func (ss *StateSpace) DeadEnds(isFinal func(State) bool) []*DeadEnd {
+	deadEnds := []*DeadEnd{}
+	for _, s := range ss.Order {
+		if !ss.deadEnd[s] {
+			continue
+		}
+		state := ss.States[s]
+		if isFinal != nil && isFinal(state) {
+			continue
+		}
+		deadEnds = append(deadEnds, &DeadEnd{State: state, Path: ss.PathTo(state)})
+	}
+	return deadEnds
+}
+
+// CheckDeadEnds explores a model from an initial state and returns
+// a *DeadEndError if any non-final dead-end state is found. For
+// instance, in a test:
+//
+//	if err := CheckDeadEnds(model, initial, ExploreLimits{}, nil); err != nil {
+//	        t.Fatal(err)
+//	}
+func CheckDeadEnds(m Walkable, initial State, limits ExploreLimits, isFinal func(State) bool) error {
+	deadEnds := Explore(m, initial, limits).DeadEnds(isFinal)
+	if len(deadEnds) == 0 {
+		return nil
+	}
+	return &DeadEndError{DeadEnds: deadEnds}
+}
+
+// Nondeterminism is an action that leads from a state to more than
+// one end state.
+type Nondeterminism struct {
+	State     State   // Start state.
+	Action    string  // Action.String() of the nondeterministic action.
+	EndStates []State // Alternative end states.
+	Path      Path    // Shortest path from the initial state to the State.
+}
+
+// NondeterminismError is returned when a model is not deterministic.
+type NondeterminismError struct {
+	Nondeterminism []*Nondeterminism
+}
+
+// Error returns nondeterministic actions and paths that lead to them.
+func (e *NondeterminismError) Error() string {
+	lines := []string{fmt.Sprintf("%d nondeterministic action(s) found", len(e.Nondeterminism))}
+	for _, nd := range e.Nondeterminism {
+		lines = append(lines, fmt.Sprintf("  %q in %s leads to %v after actions [%s]", nd.Action, nd.State, nd.EndStates, strings.Join(ActionNames(nd.Path), ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Nondeterminism returns every explored state-action pair that has
+// more than one distinct end state.
+func (ss *StateSpace) Nondeterminism() []*Nondeterminism {
+	nds := []*Nondeterminism{}
+	for _, s := range ss.Order {
+		for _, nd := range findNondeterminism(ss.out[s]) {
+			nd.Path = ss.PathTo(nd.State)
+			nds = append(nds, nd)
+		}
+	}
+	return nds
+}
+
+// CheckDeterminism explores a model from an initial state and
+// returns a *NondeterminismError if the same action leads from any
+// state to different end states.
+func CheckDeterminism(m Walkable, initial State, limits ExploreLimits) error {
+	nds := Explore(m, initial, limits).Nondeterminism()
+	if len(nds) == 0 {
+		return nil
+	}
+	return &NondeterminismError{Nondeterminism: nds}
+}
+
+// findNondeterminism returns actions that have distinct end states
+// among steps that start from the same state.
+func findNondeterminism(steps []*Step) []*Nondeterminism {
+	nds := []*Nondeterminism{}
+	ndOf := map[string]*Nondeterminism{}
+	endStrs := map[string]map[string]bool{}
+	for _, step := range steps {
+		action, end := step.action.String(), step.end.String()
+		if endStrs[action] == nil {
+			endStrs[action] = map[string]bool{}
+		}
+		if endStrs[action][end] {
+			continue
+		}
+		endStrs[action][end] = true
+		nd := ndOf[action]
+		if nd == nil {
+			nd = &Nondeterminism{State: step.start, Action: action}
+			ndOf[action] = nd
+		}
+		nd.EndStates = append(nd.EndStates, step.end)
+		if len(nd.EndStates) == 2 {
+			nds = append(nds, nd)
+		}
+	}
+	return nds
+}
@@ -0,0 +1,89 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"testing"
+)
+
+func TestCheckDeadEnds(t *testing.T) {
+	for modelName, model := range playerModels {
+		if err := CheckDeadEnds(model, &PlayerState{false, 1}, ExploreLimits{}, nil); err != nil {
+			t.Fatalf("model %q: %s", modelName, err)
+		}
+	}
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("a").Do(gotoMyState("A"))),
+			When(ms == "start", OnAction("b").Do(gotoMyState("B"))),
+			When(ms == "B", OnAction("c").Do(gotoMyState("C"))),
+		)
+	})
+	err := CheckDeadEnds(model, MyState("start"), ExploreLimits{}, nil)
+	deErr, ok := err.(*DeadEndError)
+	if !ok || len(deErr.DeadEnds) != 2 {
+		t.Fatalf("expected 2 dead ends, got %v", err)
+	}
+	if de := deErr.DeadEnds[1]; de.State.String() != "C" || len(de.Path) != 2 {
+		t.Fatalf("expected dead end C after 2 steps, got %s after %v", de.State, de.Path)
+	}
+	t.Log(err)
+	isFinal := func(s State) bool { return s.String() == "A" || s.String() == "C" }
+	if err := CheckDeadEnds(model, MyState("start"), ExploreLimits{}, isFinal); err != nil {
+		t.Fatalf("expected no dead ends with final states A and C, got %s", err)
+	}
+	if err := CheckDeadEnds(model, MyState("start"), ExploreLimits{MaxDepth: 1}, isFinal); err != nil {
+		t.Fatalf("expected no dead ends in truncated state space, got %s", err)
+	}
+}
+
+func TestCheckDeterminism(t *testing.T) {
+	for modelName, model := range playerModels {
+		if err := CheckDeterminism(model, &PlayerState{false, 1}, ExploreLimits{}); err != nil {
+			t.Fatalf("model %q: %s", modelName, err)
+		}
+	}
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		return When(true,
+			When(ms == "start", OnAction("a").Do(gotoMyState("A"))),
+			When(ms == "A", OnAction("b").Do(gotoMyState("B"))),
+			When(ms == "A", OnAction("b").Do(gotoMyState("C"))),
+			When(ms == "A", OnAction("c").Do(gotoMyState("C"))),
+			When(ms == "A", OnAction("c").Do(gotoMyState("C"))),
+		)
+	})
+	err := CheckDeterminism(model, MyState("start"), ExploreLimits{})
+	ndErr, ok := err.(*NondeterminismError)
+	if !ok || len(ndErr.Nondeterminism) != 1 {
+		t.Fatalf("expected 1 nondeterministic action, got %v", err)
+	}
+	if nd := ndErr.Nondeterminism[0]; nd.Action != "b" || len(nd.EndStates) != 2 || len(nd.Path) != 1 {
+		t.Fatalf("expected action b with 2 end states after 1 step, got %q with %v after %v", nd.Action, nd.EndStates, nd.Path)
+	}
+	t.Log(err)
+
+	model.SetStrict(true)
+	model.StepsFrom(MyState("start"))
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected panic from StepsFrom in strict mode")
+		}
+	}()
+	model.StepsFrom(MyState("A"))
+}
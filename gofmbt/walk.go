@@ -15,7 +15,22 @@
 package gofmbt
 
 import (
+	"context"
 	"iter"
+	"math/rand"
+)
+
+const (
+	WalkDepthFirst         = iota // Yield all maximal paths in depth-first order.
+	WalkBreadthFirst              // Yield all paths, shorter paths first.
+	WalkIterativeDeepening        // Yield all paths, shorter paths first, using less memory than breadth-first.
+	WalkRandom                    // Yield one random path, choosing steps by weights of their actions.
+)
+
+const (
+	PruneNone        = iota // Enumerate all paths.
+	PruneStates             // Do not extend paths from a state that has been extended before.
+	PruneStateDepths        // Do not extend paths from a state that has been extended before at equal or smaller depth.
 )
 
 type StepFilter func([]*Step) []*Step
@@ -23,6 +38,9 @@ type StepFilter func([]*Step) []*Step
 type Walker struct {
 	m          Walkable
 	stepFilter StepFilter
+	strategy   int        // One of Walk* constants.
+	rand       *rand.Rand // Random number generator for WalkRandom.
+	pruning    int        // One of Prune* constants.
 }
 
 func NewWalker(m Walkable) *Walker {
@@ -33,37 +51,184 @@ func (w *Walker) SetStepFilter(f StepFilter) {
 	w.stepFilter = f
 }
 
-// IterPaths yields all possible paths of at most maxLen steps
-// starting from a state. Note: paths are yielded as slices of the
-// same list. Therefore a yielded path must be copied in order to save
-// it.
+// SetStrategy sets the walk strategy that defines which paths are
+// yielded and in which order. The default strategy is
+// WalkDepthFirst.
+func (w *Walker) SetStrategy(strategy int) {
+	w.strategy = strategy
+}
+
+// SetRandomSeed sets the seed of random walks.
+func (w *Walker) SetRandomSeed(seed int64) {
+	w.rand = rand.New(rand.NewSource(seed))
+}
+
+// SetPruning sets pruning of paths that revisit states within one
+// enumeration of paths. PruneStates extends paths from every state
+// only once, which is fastest but may skip long paths that visit
+// states seen earlier at larger depth. PruneStateDepths extends paths
+// from a state again only if it is reached with fewer steps than
+// before, that is with more steps remaining. A pruned path is yielded
+// but not extended. Pruning does not affect WalkRandom.
+func (w *Walker) SetPruning(pruning int) {
+	w.pruning = pruning
+}
+
+// prune returns true if paths should not be extended from state s at
+// depth. Otherwise it records that s has been extended at depth.
+func (w *Walker) prune(seen map[string]int, s State, depth int) bool {
+	if w.pruning == PruneNone {
+		return false
+	}
+	key := s.String()
+	if seenDepth, ok := seen[key]; ok && (w.pruning == PruneStates || seenDepth <= depth) {
+		return true
+	}
+	seen[key] = depth
+	return false
+}
+
+func (w *Walker) stepsFrom(s State) []*Step {
+	steps := w.m.StepsFrom(s)
+	if w.stepFilter != nil {
+		steps = w.stepFilter(steps)
+	}
+	return steps
+}
+
+// IterPaths yields paths of at most maxLen steps starting from a
+// state. With the default WalkDepthFirst strategy it yields all
+// possible paths of maxLen steps, and shorter paths only if they end
+// to a state without steps. WalkBreadthFirst and
+// WalkIterativeDeepening yield all paths of 1...maxLen steps, shorter
+// paths first. WalkRandom yields a single path of maxLen steps, or
+// shorter if it ends to a state without steps. Note: paths are
+// yielded as slices of the same list. Therefore a yielded path must
+// be copied in order to save it.
 func (w *Walker) IterPaths(s State, maxLen int) iter.Seq[Path] {
+	return w.IterPathsContext(context.Background(), s, maxLen)
+}
+
+// IterPathsContext is like IterPaths, but it stops yielding paths when
+// the context is cancelled or its deadline is exceeded.
+func (w *Walker) IterPathsContext(ctx context.Context, s State, maxLen int) iter.Seq[Path] {
 	path := make(Path, maxLen, maxLen)
 	return func(yield func(Path) bool) {
-		w.yieldPaths(yield, &path, 0, s, maxLen)
+		switch w.strategy {
+		case WalkBreadthFirst:
+			w.yieldPathsBreadthFirst(ctx.Done(), yield, s, maxLen, map[string]int{})
+		case WalkIterativeDeepening:
+			for depth := 1; depth <= maxLen; depth++ {
+				found := false
+				if !w.yieldPathsOfLen(ctx.Done(), yield, &path, 0, s, depth, &found, map[string]int{}) || !found {
+					return
+				}
+			}
+		case WalkRandom:
+			w.yieldRandomPath(ctx.Done(), yield, path, s)
+		default:
+			w.yieldPaths(ctx.Done(), yield, &path, 0, s, maxLen, map[string]int{})
+		}
 	}
 }
 
-func (w *Walker) yieldPaths(yield func(Path) bool, path *Path, index int, s State, maxLen int) bool {
+func (w *Walker) yieldPaths(done <-chan struct{}, yield func(Path) bool, path *Path, index int, s State, maxLen int, seen map[string]int) bool {
+	select {
+	case <-done:
+		return false
+	default:
+	}
 	if index == maxLen {
 		return yield(*path)
 	}
-	nextSteps := w.m.StepsFrom(s)
-	if w.stepFilter != nil {
-		nextSteps = w.stepFilter(nextSteps)
+	if w.prune(seen, s, index) {
+		return yield((*path)[:index])
 	}
+	nextSteps := w.stepsFrom(s)
 	if len(nextSteps) == 0 {
 		return yield((*path)[:index])
 	}
 	for _, step := range nextSteps {
 		(*path)[index] = step
-		if !w.yieldPaths(yield, path, index+1, step.EndState(), maxLen) {
+		if !w.yieldPaths(done, yield, path, index+1, step.EndState(), maxLen, seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// yieldPathsOfLen yields paths of exactly pathLen steps. found is set
+// to true if any path of pathLen steps exists.
+func (w *Walker) yieldPathsOfLen(done <-chan struct{}, yield func(Path) bool, path *Path, index int, s State, pathLen int, found *bool, seen map[string]int) bool {
+	select {
+	case <-done:
+		return false
+	default:
+	}
+	if index == pathLen {
+		*found = true
+		return yield((*path)[:index])
+	}
+	if w.prune(seen, s, index) {
+		return true
+	}
+	for _, step := range w.stepsFrom(s) {
+		(*path)[index] = step
+		if !w.yieldPathsOfLen(done, yield, path, index+1, step.EndState(), pathLen, found, seen) {
 			return false
 		}
 	}
 	return true
 }
 
+func (w *Walker) yieldPathsBreadthFirst(done <-chan struct{}, yield func(Path) bool, s State, maxLen int, seen map[string]int) {
+	w.prune(seen, s, 0)
+	queue := []Path{{}}
+	for len(queue) > 0 {
+		prefix := queue[0]
+		queue = queue[1:]
+		last := s
+		if len(prefix) > 0 {
+			last = prefix[len(prefix)-1].EndState()
+		}
+		for _, step := range w.stepsFrom(last) {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			path := append(prefix[:len(prefix):len(prefix)], step)
+			if !yield(path) {
+				return
+			}
+			if len(path) < maxLen && !w.prune(seen, step.EndState(), len(path)) {
+				queue = append(queue, path)
+			}
+		}
+	}
+}
+
+func (w *Walker) yieldRandomPath(done <-chan struct{}, yield func(Path) bool, path Path, s State) {
+	if w.rand == nil {
+		w.SetRandomSeed(0)
+	}
+	for index := range path {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		nextSteps := w.stepsFrom(s)
+		if len(nextSteps) == 0 {
+			path = path[:index]
+			break
+		}
+		path[index] = weightedChoice(w.rand, nextSteps)
+		s = path[index].EndState()
+	}
+	yield(path)
+}
+
 // Paths returns all alternative paths of at most maxLen steps
 // that start from a given state.
 func (w *Walker) Paths(s State, maxLen int) []Path {
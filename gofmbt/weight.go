@@ -0,0 +1,72 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// equalWeights returns true if actions of all steps have equal weights.
+func equalWeights(steps []*Step) bool {
+	for _, step := range steps[min(1, len(steps)):] {
+		if step.action.weight != steps[0].action.weight {
+			return false
+		}
+	}
+	return true
+}
+
+// weightedChoice returns a random step. The probability of a step is
+// proportional to the weight of its action. If all weights are zero,
+// every step is equally likely.
+func weightedChoice(r *rand.Rand, steps []*Step) *Step {
+	total := 0.0
+	for _, step := range steps {
+		total += max(step.action.weight, 0)
+	}
+	if total == 0 {
+		return steps[r.Intn(len(steps))]
+	}
+	x := r.Float64() * total
+	for _, step := range steps {
+		x -= max(step.action.weight, 0)
+		if x < 0 {
+			return step
+		}
+	}
+	return steps[len(steps)-1]
+}
+
+// weightedShuffle shuffles steps so that the probability of a step to
+// be the first one is proportional to the weight of its action, and
+// the same applies to the rest of the steps recursively. Steps with
+// zero weight are shuffled last.
+func weightedShuffle(r *rand.Rand, steps []*Step) {
+	keys := make(map[*Step]float64, len(steps))
+	for _, step := range steps {
+		// Exponentially distributed keys with rate weight:
+		// the smallest key wins with probability
+		// proportional to the weight.
+		keys[step] = math.Inf(1)
+		if step.action.weight > 0 {
+			keys[step] = r.ExpFloat64() / step.action.weight
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return keys[steps[i]] < keys[steps[j]]
+	})
+}
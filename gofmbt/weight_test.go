@@ -0,0 +1,72 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func newWeightedModel() *Model {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		return When(true,
+			OnAction("rare").WithWeight(1).Do(gotoMyState("s")),
+			OnAction("common").WithWeight(9).Do(gotoMyState("s")),
+			OnAction("never").WithWeight(0).Do(gotoMyState("s")),
+		)
+	})
+	return model
+}
+
+func TestWeightedRandomWalk(t *testing.T) {
+	if w := NewAction("a").Weight(); w != 1 {
+		t.Fatalf("expected default weight 1, got %v", w)
+	}
+	w := NewWalker(newWeightedModel())
+	w.SetStrategy(WalkRandom)
+	w.SetRandomSeed(1)
+	counts := map[string]int{}
+	for path := range w.IterPaths(MyState("s"), 1000) {
+		for _, step := range path {
+			counts[step.Action().String()]++
+		}
+	}
+	if counts["never"] != 0 {
+		t.Fatalf("expected zero-weight action never chosen, got %v", counts)
+	}
+	if counts["common"] < 850 || counts["common"] > 950 {
+		t.Fatalf("expected common chosen about 900 times, got %v", counts)
+	}
+}
+
+func TestWeightedShuffle(t *testing.T) {
+	steps := newWeightedModel().StepsFrom(MyState("s"))
+	r := rand.New(rand.NewSource(1))
+	firsts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		shuffled := stepShuffler(r)(steps)
+		if len(shuffled) != 3 || shuffled[2].Action().String() != "never" {
+			t.Fatalf("expected zero-weight step last, got %v", shuffled)
+		}
+		firsts[shuffled[0].Action().String()]++
+	}
+	if firsts["common"] < 850 || firsts["common"] > 950 {
+		t.Fatalf("expected common first about 900 times, got %v", firsts)
+	}
+	if steps[0].Action().String() != "rare" {
+		t.Fatalf("expected original steps unchanged, got %v", steps)
+	}
+}
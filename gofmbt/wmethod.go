@@ -0,0 +1,249 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ConformanceTest is a test case in a conformance test suite. It is
+// executed from the initial state of the model. The system under
+// test conforms to the model if it executes actions of the Path with
+// expected outputs (Action.Output()), and then refuses the Refused
+// action.
+type ConformanceTest struct {
+	Path    Path   // Steps to execute.
+	Refused string // Action that must not be possible after the Path, or "".
+}
+
+// String returns actions, their expected outputs and the refused
+// action of a test.
+func (ct *ConformanceTest) String() string {
+	parts := []string{}
+	for _, step := range ct.Path {
+		parts = append(parts, step.action.String()+"/"+step.action.output)
+	}
+	if ct.Refused != "" {
+		parts = append(parts, "!"+ct.Refused)
+	}
+	return strings.Join(parts, " ")
+}
+
+// WMethod generates a conformance test suite for a deterministic
+// finite model with the W-method. States are distinguished by their
+// observable behavior: outputs of actions and whether or not actions
+// are possible. The suite contains every sequence of a transition
+// cover (paths to every state, followed by any action), followed by
+// up to extraStates arbitrary actions, followed by every sequence of
+// a characterization set that distinguishes all distinguishable
+// states. If the system under test has at most extraStates more
+// states than the model, passing the suite proves that it conforms
+// to the model. Returns a *NondeterminismError if the model is not
+// deterministic, and an error if limits prevent exploring the whole
+// model, because unexplored states would seem to refuse every action.
+func WMethod(m Walkable, initial State, limits ExploreLimits, extraStates int) ([]*ConformanceTest, error) {
+	ss := Explore(m, initial, limits)
+	if ss.Truncated {
+		return nil, errors.New("model is not fully explored within limits")
+	}
+	if nds := ss.Nondeterminism(); len(nds) > 0 {
+		return nil, &NondeterminismError{Nondeterminism: nds}
+	}
+	fsm := newOutputMachine(ss)
+	transitionCover := [][]string{}
+	for _, s := range ss.Order {
+		access := ActionNames(ss.PathTo(ss.States[s]))
+		transitionCover = append(transitionCover, access)
+		for _, action := range fsm.alphabet {
+			transitionCover = append(transitionCover, append(access[:len(access):len(access)], action))
+		}
+	}
+	middles := [][]string{{}}
+	for prev := middles; extraStates > 0; extraStates-- {
+		next := [][]string{}
+		for _, middle := range prev {
+			for _, action := range fsm.alphabet {
+				next = append(next, append(middle[:len(middle):len(middle)], action))
+			}
+		}
+		middles = append(middles, next...)
+		prev = next
+	}
+	suffixes := append([][]string{{}}, fsm.characterizationSet()...)
+
+	tests := []*ConformanceTest{}
+	seen := map[string]bool{}
+	for _, p := range transitionCover {
+		for _, middle := range middles {
+			for _, suffix := range suffixes {
+				test := fsm.test(append(append(append([]string{}, p...), middle...), suffix...))
+				if key := test.String(); !seen[key] {
+					seen[key] = true
+					tests = append(tests, test)
+				}
+			}
+		}
+	}
+	return removePrefixTests(tests), nil
+}
+
+// removePrefixTests removes tests that are prefixes of other tests.
+func removePrefixTests(tests []*ConformanceTest) []*ConformanceTest {
+	keys := make([]string, len(tests))
+	for i, test := range tests {
+		keys[i] = test.String()
+	}
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+	isPrefix := map[string]bool{}
+	for i := 0; i+1 < len(sorted); i++ {
+		if sorted[i] == "" || strings.HasPrefix(sorted[i+1], sorted[i]+" ") {
+			isPrefix[sorted[i]] = true
+		}
+	}
+	kept := []*ConformanceTest{}
+	for i, test := range tests {
+		if test.Refused != "" || !isPrefix[keys[i]] {
+			kept = append(kept, test)
+		}
+	}
+	return kept
+}
+
+// outputMachine is a deterministic finite state machine with outputs.
+type outputMachine struct {
+	ss       *StateSpace
+	alphabet []string
+	delta    map[string]map[string]*Step // State, action -> step.
+}
+
+func newOutputMachine(ss *StateSpace) *outputMachine {
+	fsm := &outputMachine{ss: ss, delta: map[string]map[string]*Step{}}
+	actions := map[string]bool{}
+	for _, s := range ss.Order {
+		fsm.delta[s] = map[string]*Step{}
+		for _, step := range ss.out[s] {
+			fsm.delta[s][step.action.String()] = step
+			actions[step.action.String()] = true
+		}
+	}
+	for action := range actions {
+		fsm.alphabet = append(fsm.alphabet, action)
+	}
+	sort.Strings(fsm.alphabet)
+	return fsm
+}
+
+// output returns the observable output of an action in a state,
+// and the step, or nil if the action is refused.
+func (fsm *outputMachine) output(s string, action string) (string, *Step) {
+	step := fsm.delta[s][action]
+	if step == nil {
+		return "\x00refused", nil
+	}
+	return step.action.output, step
+}
+
+// distinguishes returns true if an action sequence produces
+// different observations in two states.
+func (fsm *outputMachine) distinguishes(seq []string, s1, s2 string) bool {
+	for _, action := range seq {
+		out1, step1 := fsm.output(s1, action)
+		out2, step2 := fsm.output(s2, action)
+		if out1 != out2 {
+			return true
+		}
+		if step1 == nil {
+			return false
+		}
+		s1, s2 = step1.end.String(), step2.end.String()
+	}
+	return false
+}
+
+// distinguishingSequence returns a shortest action sequence that
+// distinguishes two states, or nil if they are equivalent.
+func (fsm *outputMachine) distinguishingSequence(s1, s2 string) []string {
+	type pair struct {
+		s1, s2 string
+		seq    []string
+	}
+	visited := map[[2]string]bool{{s1, s2}: true}
+	queue := []pair{{s1, s2, []string{}}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, action := range fsm.alphabet {
+			seq := append(p.seq[:len(p.seq):len(p.seq)], action)
+			out1, step1 := fsm.output(p.s1, action)
+			out2, step2 := fsm.output(p.s2, action)
+			if out1 != out2 {
+				return seq
+			}
+			if step1 == nil {
+				continue
+			}
+			next := [2]string{step1.end.String(), step2.end.String()}
+			if next[0] != next[1] && !visited[next] {
+				visited[next] = true
+				queue = append(queue, pair{next[0], next[1], seq})
+			}
+		}
+	}
+	return nil
+}
+
+// characterizationSet returns action sequences that distinguish
+// every pair of distinguishable states.
+func (fsm *outputMachine) characterizationSet() [][]string {
+	w := [][]string{}
+	for i, s1 := range fsm.ss.Order {
+		for _, s2 := range fsm.ss.Order[i+1:] {
+			distinguished := false
+			for _, seq := range w {
+				if fsm.distinguishes(seq, s1, s2) {
+					distinguished = true
+					break
+				}
+			}
+			if distinguished {
+				continue
+			}
+			if seq := fsm.distinguishingSequence(s1, s2); seq != nil {
+				w = append(w, seq)
+			}
+		}
+	}
+	return w
+}
+
+// test returns a test that executes an action sequence from the
+// initial state until the end or the first refused action.
+func (fsm *outputMachine) test(seq []string) *ConformanceTest {
+	test := &ConformanceTest{Path: Path{}}
+	s := fsm.ss.Order[0]
+	for _, action := range seq {
+		_, step := fsm.output(s, action)
+		if step == nil {
+			test.Refused = action
+			break
+		}
+		test.Path = append(test.Path, step)
+		s = step.end.String()
+	}
+	return test
+}
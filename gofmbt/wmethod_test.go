@@ -0,0 +1,103 @@
+// Copyright Antti Kervinen. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you
+// may not use this file except in compliance with the License.  You
+// may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied.  See the License for the specific language governing
+// permissions and limitations under the License.
+
+package gofmbt
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newCounterModel returns a counter modulo 3 that outputs "wrap" when
+// wrapping around. If faulty, incrementing 1 leads back to 1.
+func newCounterModel(faulty bool) *Model {
+	model := NewModel()
+	model.From(func(s State) []*Transition {
+		ms := s.(MyState)
+		next := map[MyState]string{"0": "1", "1": "2", "2": "0"}[ms]
+		if faulty && ms == "1" {
+			next = "1"
+		}
+		output := "ok"
+		if ms == "2" {
+			output = "wrap"
+		}
+		return When(true,
+			OnAction("inc").WithOutput(output).Do(gotoMyState(next)),
+			When(ms != "0", OnAction("reset").WithOutput("ok").Do(gotoMyState("0"))),
+		)
+	})
+	return model
+}
+
+// conforms returns an error if a model does not pass a conformance test.
+func conforms(m Walkable, initial State, test *ConformanceTest) error {
+	s := initial
+	for i, expected := range test.Path {
+		step := matchingStep(m.StepsFrom(s), expected.Action().String(), "")
+		if step == nil {
+			return fmt.Errorf("step %d: %s refused", i, expected.Action())
+		}
+		if step.Action().Output() != expected.Action().Output() {
+			return fmt.Errorf("step %d: %s: expected output %q, got %q", i, expected.Action(), expected.Action().Output(), step.Action().Output())
+		}
+		s = step.EndState()
+	}
+	if test.Refused != "" && matchingStep(m.StepsFrom(s), test.Refused, "") != nil {
+		return fmt.Errorf("%s not refused", test.Refused)
+	}
+	return nil
+}
+
+func TestWMethod(t *testing.T) {
+	model := newCounterModel(false)
+	tests, err := WMethod(model, MyState("0"), ExploreLimits{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Log(test)
+		if err := conforms(model, MyState("0"), test); err != nil {
+			t.Fatalf("model does not pass its own test %s: %s", test, err)
+		}
+	}
+	fsm := newOutputMachine(model.Explore(MyState("0"), ExploreLimits{}))
+	w := fsm.characterizationSet()
+	for _, pair := range [][2]string{{"0", "1"}, {"0", "2"}, {"1", "2"}} {
+		distinguished := false
+		for _, seq := range w {
+			distinguished = distinguished || fsm.distinguishes(seq, pair[0], pair[1])
+		}
+		if !distinguished {
+			t.Fatalf("characterization set %v does not distinguish %v", w, pair)
+		}
+	}
+
+	failed := 0
+	for _, test := range tests {
+		if conforms(newCounterModel(true), MyState("0"), test) != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		t.Fatalf("faulty model passed all %d tests", len(tests))
+	}
+
+	if _, err := WMethod(playerModels["when"], &PlayerState{false, 1}, ExploreLimits{}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WMethod(model, MyState("0"), ExploreLimits{MaxDepth: 1}, 0); err == nil {
+		t.Fatal("expected error when exploration is truncated")
+	}
+}